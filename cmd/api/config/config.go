@@ -8,13 +8,25 @@ import (
 	"strings"
 	"time"
 
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/checker"
 	"github.com/oxylume/index/internal/crawler"
-	"github.com/xssnick/tonutils-go/address"
+	"github.com/oxylume/index/internal/db"
 )
 
 const (
 	defaultBagTTL        = 3600 // 1 hour
 	defaultCheckInterval = 7200 // 2 hours
+	defaultRankInterval  = 600  // 10 minutes
+	defaultStatsInterval = 3600 // 1 hour
+	defaultStatsRefresh  = 60   // 1 minute
+	defaultCheckWorkers  = 100
+	defaultUpstreamLimit = 2
+	defaultSpiderDepth   = 2
+	defaultSpiderPages   = 32
+	defaultSpiderBytes   = 4 << 20 // 4 MiB
+	defaultSnapshotAge   = 7776000 // 90 days
+	defaultSnapshotCount = 50
 )
 
 // todo: automatically resolve domain zone of passed source
@@ -30,9 +42,21 @@ type Config struct {
 	BagTTL        time.Duration
 	DatabaseUrl   string
 	CheckInterval time.Duration
+	CheckWorkers  int
+	// UpstreamLimit limits concurrent checks of sites sharing an ADNL server or a bag
+	UpstreamLimit int
+	ZoneWeights   map[string]float64
+	RankInterval  time.Duration
+	StatsInterval time.Duration
+	StatsRefresh  time.Duration
 	ToncenterUrl  string
 	ToncenterKey  string
 	DomainSources []*crawler.DomainSource
+	Spider        checker.SpiderConfig
+	TaxonomyFile  string
+	Snapshots     db.SnapshotRetention
+	GeoIPFile     string
+	Probes        map[string]checker.ProbeConfig
 }
 
 func LoadConfig() (*Config, error) {
@@ -46,7 +70,7 @@ func LoadConfig() (*Config, error) {
 		if !strings.HasPrefix(zone, ".") {
 			return nil, fmt.Errorf("DOMAIN_SOURCES zone must begin with a \".\", got %q", zone)
 		}
-		addr, err := parseAddress(rawAddr)
+		addr, err := api.ParseAddress(rawAddr)
 		if err != nil {
 			return nil, fmt.Errorf("invalid DOMAIN_SOURCES address %s: %w", rawAddr, err)
 		}
@@ -55,6 +79,63 @@ func LoadConfig() (*Config, error) {
 			Zone:    zone,
 		}
 	}
+	workers := getEnvInt("CHECK_WORKERS", defaultCheckWorkers)
+	if workers <= 0 {
+		return nil, fmt.Errorf("CHECK_WORKERS must be positive, got %d", workers)
+	}
+	// 0 disables the limit, negative values are likely typos
+	upstreamLimit := getEnvInt("CHECK_UPSTREAM_LIMIT", defaultUpstreamLimit)
+	if upstreamLimit < 0 {
+		return nil, fmt.Errorf("CHECK_UPSTREAM_LIMIT must not be negative, got %d", upstreamLimit)
+	}
+	weights := make(map[string]float64)
+	for _, raw := range getEnvMany("CHECK_ZONE_WEIGHTS") {
+		zone, rawWeight, ok := strings.Cut(raw, ":")
+		if !ok {
+			return nil, fmt.Errorf("unexpected CHECK_ZONE_WEIGHTS item format %s, must be <zone>:<weight>", raw)
+		}
+		weight, err := strconv.ParseFloat(rawWeight, 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("CHECK_ZONE_WEIGHTS weight of %s must be a positive number, got %q", zone, rawWeight)
+		}
+		weights[zone] = weight
+	}
+	spider := checker.SpiderConfig{
+		Enabled:  getEnvBool("SPIDER_ENABLED", false),
+		MaxDepth: getEnvInt("SPIDER_MAX_DEPTH", defaultSpiderDepth),
+		MaxPages: getEnvInt("SPIDER_MAX_PAGES", defaultSpiderPages),
+		MaxBytes: int64(getEnvInt("SPIDER_MAX_BYTES", defaultSpiderBytes)),
+	}
+	if spider.Enabled {
+		if spider.MaxDepth <= 0 {
+			return nil, fmt.Errorf("SPIDER_MAX_DEPTH must be positive, got %d", spider.MaxDepth)
+		}
+		if spider.MaxPages <= 0 {
+			return nil, fmt.Errorf("SPIDER_MAX_PAGES must be positive, got %d", spider.MaxPages)
+		}
+		if spider.MaxBytes <= 0 {
+			return nil, fmt.Errorf("SPIDER_MAX_BYTES must be positive, got %d", spider.MaxBytes)
+		}
+	}
+	// 0 keeps snapshots forever, negative values are likely typos
+	snapshots := db.SnapshotRetention{
+		MaxAge:   time.Duration(getEnvInt("SNAPSHOT_MAX_AGE", defaultSnapshotAge)) * time.Second,
+		MaxCount: getEnvInt("SNAPSHOT_MAX_COUNT", defaultSnapshotCount),
+	}
+	if snapshots.MaxAge < 0 {
+		return nil, fmt.Errorf("SNAPSHOT_MAX_AGE must not be negative, got %d", int(snapshots.MaxAge.Seconds()))
+	}
+	if snapshots.MaxCount < 0 {
+		return nil, fmt.Errorf("SNAPSHOT_MAX_COUNT must not be negative, got %d", snapshots.MaxCount)
+	}
+	probes := make(map[string]checker.ProbeConfig)
+	for _, name := range checker.ProbeNames() {
+		key := "PROBE_" + strings.ToUpper(name)
+		probes[name] = checker.ProbeConfig{
+			Disabled: !getEnvBool(key+"_ENABLED", true),
+			Timeout:  time.Duration(getEnvInt(key+"_TIMEOUT", 0)) * time.Second,
+		}
+	}
 	return &Config{
 		ApiListen:     getEnv("API_LISTEN", ":8081"),
 		GatewayListen: getEnv("GATEWAY_LISTEN", ":8082"),
@@ -62,9 +143,20 @@ func LoadConfig() (*Config, error) {
 		BagTTL:        time.Duration(getEnvInt("BAG_TTL", defaultBagTTL)) * time.Second,
 		DatabaseUrl:   getEnv("DATABASE_URL", "postgres://postgres@localhost:5432/tonsite?sslmode=disable"),
 		CheckInterval: time.Duration(getEnvInt("CHECK_INTERVAL", defaultCheckInterval)) * time.Second,
+		CheckWorkers:  workers,
+		UpstreamLimit: upstreamLimit,
+		ZoneWeights:   weights,
+		RankInterval:  time.Duration(getEnvInt("RANK_INTERVAL", defaultRankInterval)) * time.Second,
+		StatsInterval: time.Duration(getEnvInt("STATS_INTERVAL", defaultStatsInterval)) * time.Second,
+		StatsRefresh:  time.Duration(getEnvInt("STATS_REFRESH_INTERVAL", defaultStatsRefresh)) * time.Second,
 		ToncenterUrl:  getEnv("TONCENTER_URL", "https://toncenter.com/api"),
 		ToncenterKey:  getEnv("TONCENTER_KEY", ""),
 		DomainSources: sources,
+		TaxonomyFile:  getEnv("TAXONOMY_FILE", ""),
+		GeoIPFile:     getEnv("GEOIP_FILE", ""),
+		Probes:        probes,
+		Spider:        spider,
+		Snapshots:     snapshots,
 	}, nil
 }
 
@@ -99,12 +191,14 @@ func getEnvInt(key string, defaultValue int) int {
 	return val
 }
 
-func parseAddress(addr string) (*address.Address, error) {
-	parsed, err := address.ParseAddr(addr)
+func getEnvBool(key string, defaultValue bool) bool {
+	env, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	val, err := strconv.ParseBool(env)
 	if err != nil {
-		if parsed, err = address.ParseRawAddr(addr); err != nil {
-			return nil, err
-		}
+		log.Fatalf("invalid boolean value for env %s: %v", key, err)
 	}
-	return parsed, nil
+	return val
 }
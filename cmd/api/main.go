@@ -18,7 +18,10 @@ import (
 	"github.com/oxylume/index/internal/checker"
 	"github.com/oxylume/index/internal/crawler"
 	"github.com/oxylume/index/internal/db"
+	"github.com/oxylume/index/internal/history"
+	"github.com/oxylume/index/internal/ranker"
 	"github.com/oxylume/index/pkg/api/toncenter"
+	"github.com/oxylume/index/pkg/geoip"
 	"github.com/oxylume/index/pkg/proxy"
 	"github.com/xssnick/tonutils-go/adnl"
 	"github.com/xssnick/tonutils-go/adnl/dht"
@@ -100,21 +103,53 @@ func main() {
 	must(dbPool.Ping(ctx))
 	sites := db.NewSitesStore(dbPool)
 	crawlerState := db.NewCrawlerStore(dbPool)
+	links := db.NewLinksStore(dbPool)
+	pages := db.NewPagesStore(dbPool)
+	favicons := db.NewFaviconsStore(dbPool)
+	clearnet := db.NewClearnetStore(dbPool)
+	audits := db.NewAuditStore(dbPool)
+	snapshots := db.NewSnapshotsStore(dbPool)
+	hosting := db.NewHostingStore(dbPool)
+	probes := db.NewProbesStore(dbPool)
+	statsHistory := db.NewHistoryStore(dbPool)
 
 	tcClient := toncenter.NewClient(cfg.ToncenterUrl, cfg.ToncenterKey)
 
 	crawler := crawler.NewCrawler(dnsClient, bags, rldp, sites, crawlerState, tcClient)
 	crawler.Start(ctx, cfg.DomainSources)
 	defer crawler.Close()
-	checker := checker.NewChecker(dnsClient, bags, rldp, sites, cfg.CheckInterval)
-	checker.Start(ctx, 100)
-	defer checker.Close()
-
 	zones := make([]string, len(cfg.DomainSources))
 	for i, src := range cfg.DomainSources {
 		zones[i] = src.Zone
 	}
-	handler := handler.NewHandler(dnsClient, bags, rldp, sites, zones)
+	taxonomy := must1(checker.LoadTaxonomy(cfg.TaxonomyFile))
+	var geoDB *geoip.DB
+	if cfg.GeoIPFile != "" {
+		geoDB = must1(geoip.Load(cfg.GeoIPFile))
+	}
+	checker := checker.NewChecker(dnsClient, bags, rldp, sites, links, pages, favicons, clearnet, audits, snapshots, hosting, probes, checker.Config{
+		CheckInterval: cfg.CheckInterval,
+		Zones:         zones,
+		Spider:        cfg.Spider,
+		Taxonomy:      taxonomy,
+		Snapshots:     cfg.Snapshots,
+		GeoIP:         geoDB,
+		UpstreamLimit: cfg.UpstreamLimit,
+		ZoneWeights:   cfg.ZoneWeights,
+		Probes:        cfg.Probes,
+	})
+	checker.Start(ctx, cfg.CheckWorkers)
+	defer checker.Close()
+
+	ranker := ranker.NewRanker(sites, cfg.RankInterval)
+	ranker.Start(ctx)
+	defer ranker.Close()
+
+	recorder := history.NewRecorder(sites, statsHistory, cfg.StatsRefresh, cfg.StatsInterval)
+	recorder.Start(ctx)
+	defer recorder.Close()
+
+	handler := handler.NewHandler(dnsClient, bags, rldp, sites, links, pages, favicons, clearnet, audits, snapshots, hosting, probes, statsHistory, crawlerState, ranker, cfg.DomainSources)
 
 	mux := http.NewServeMux()
 
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+)
+
+type getAuditResponse struct {
+	BrokenLinks   int                  `json:"brokenLinks"`
+	MissingAssets int                  `json:"missingAssets"`
+	Issues        []auditIssueResponse `json:"issues"`
+	Cursor        string               `json:"cursor,omitempty"`
+}
+
+type auditIssueResponse struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"`
+	Status int    `json:"status"`
+}
+
+var auditKinds = map[db.AuditKind]string{
+	db.AuditLink:  "link",
+	db.AuditAsset: "asset",
+}
+
+func (h *Handler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, limit, err := parseKeyPage(r.URL.Query())
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	links, assets, err := h.audits.Count(r.Context(), domain)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	issues, err := h.audits.List(r.Context(), domain, after, limit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respIssues := make([]auditIssueResponse, len(issues))
+	for i, issue := range issues {
+		respIssues[i] = auditIssueResponse{
+			Source: issue.Source,
+			Target: issue.Target,
+			Kind:   auditKinds[issue.Kind],
+			Status: issue.Status,
+		}
+	}
+	var respCursor string
+	if len(issues) == limit && limit > 0 {
+		respCursor = api.EncodeCursor(&db.Cursor{Domain: issues[len(issues)-1].Target})
+	}
+	writeJson(w, getAuditResponse{
+		BrokenLinks:   links,
+		MissingAssets: assets,
+		Issues:        respIssues,
+		Cursor:        respCursor,
+	})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+)
+
+type getClearnetDepsResponse struct {
+	Hosts  map[string]int        `json:"hosts"`
+	Deps   []clearnetDepResponse `json:"deps"`
+	Cursor string                `json:"cursor,omitempty"`
+}
+
+type clearnetDepResponse struct {
+	Url  string `json:"url"`
+	Host string `json:"host"`
+	Kind string `json:"kind"`
+}
+
+func (h *Handler) GetClearnetDeps(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, limit, err := parseKeyPage(r.URL.Query())
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hosts, err := h.clearnet.CountHosts(r.Context(), domain)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	deps, err := h.clearnet.List(r.Context(), domain, after, limit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respDeps := make([]clearnetDepResponse, len(deps))
+	for i, dep := range deps {
+		respDeps[i] = clearnetDepResponse{
+			Url:  dep.Url,
+			Host: dep.Host,
+			Kind: dep.Kind,
+		}
+	}
+	var respCursor string
+	if len(deps) == limit && limit > 0 {
+		respCursor = api.EncodeCursor(&db.Cursor{Domain: deps[len(deps)-1].Url})
+	}
+	writeJson(w, getClearnetDepsResponse{
+		Hosts:  hosts,
+		Deps:   respDeps,
+		Cursor: respCursor,
+	})
+}
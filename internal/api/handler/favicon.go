@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+)
+
+func (h *Handler) GetFavicon(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	favicon, err := h.favicons.Get(r.Context(), domain)
+	if errors.Is(err, db.ErrNotFound) {
+		writeJsonError(w, http.StatusNotFound, fmt.Sprintf("favicon of %s not found", domain))
+		return
+	}
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+
+	etag := fmt.Sprintf("\"%x\"", favicon.Hash)
+	w.Header().Set("Etag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=86400, stale-while-revalidate=604800")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", favicon.ContentType)
+	w.Header().Set("Content-Length", fmt.Sprint(len(favicon.Content)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// svg icons may contain scripts, they must never run in our origin
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	w.WriteHeader(http.StatusOK)
+	w.Write(favicon.Content)
+}
@@ -38,6 +38,9 @@ func (h *Handler) ServeGateway(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if isPageView(r) {
+		h.ranker.Hit(realHost)
+	}
 	if inStorage {
 		fileName := strings.TrimPrefix(r.URL.Path, "/")
 		if fileName == "" {
@@ -176,6 +179,14 @@ func (h *Handler) resolve(ctx context.Context, host string) (id []byte, inStorag
 	return id, inStorage, err
 }
 
+// isPageView tells apart page navigations from requests of page assets
+func isPageView(r *http.Request) bool {
+	if dest := r.Header.Get("Sec-Fetch-Dest"); dest != "" {
+		return dest == "document"
+	}
+	return r.URL.Path == "/"
+}
+
 func bagEtag(bagId []byte, fileName string) (string, error) {
 	digest := md5.New()
 	if _, err := digest.Write(bagId); err != nil {
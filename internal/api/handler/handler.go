@@ -1,12 +1,17 @@
 package handler
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/oxylume/index/internal/crawler"
 	"github.com/oxylume/index/internal/db"
+	"github.com/oxylume/index/internal/ranker"
 	"github.com/oxylume/index/pkg/proxy"
+	"github.com/oxylume/index/pkg/ttlcache"
 	"github.com/xssnick/tonutils-go/ton/dns"
 )
 
@@ -17,16 +22,31 @@ type Handler struct {
 	bags       *proxy.BagProvider
 	rldp       *proxy.RLDPConnector
 	sites      *db.SitesStore
+	links      *db.LinksStore
+	pages      *db.PagesStore
+	favicons   *db.FaviconsStore
+	clearnet   *db.ClearnetStore
+	audits     *db.AuditStore
+	snapshots  *db.SnapshotsStore
+	hosting    *db.HostingStore
+	probes     *db.ProbesStore
+	history    *db.HistoryStore
+	crawler    *db.CrawlerStore
+	ranker     *ranker.Ranker
+	suggests   *ttlcache.Cache[string, []suggestionResponse]
+	stats      *ttlcache.Cache[string, cachedJson]
+	openApi    cachedJson
 	zones      map[string]struct{}
+	sources    []*crawler.DomainSource
 	namespaces []string
 }
 
-func NewHandler(dns *dns.Client, bags *proxy.BagProvider, rldp *proxy.RLDPConnector, sites *db.SitesStore, zones []string) *Handler {
-	zonesMap := make(map[string]struct{}, len(zones))
-	namespaces := make([]string, 0, len(zones)+len(specialNamespaces))
-	for _, zone := range zones {
-		zonesMap[zone] = struct{}{}
-		namespace := strings.ReplaceAll(zone, ".", "-") + "."
+func NewHandler(dns *dns.Client, bags *proxy.BagProvider, rldp *proxy.RLDPConnector, sites *db.SitesStore, links *db.LinksStore, pages *db.PagesStore, favicons *db.FaviconsStore, clearnet *db.ClearnetStore, audits *db.AuditStore, snapshots *db.SnapshotsStore, hosting *db.HostingStore, probes *db.ProbesStore, history *db.HistoryStore, crawlerState *db.CrawlerStore, ranker *ranker.Ranker, sources []*crawler.DomainSource) *Handler {
+	zonesMap := make(map[string]struct{}, len(sources))
+	namespaces := make([]string, 0, len(sources)+len(specialNamespaces))
+	for _, src := range sources {
+		zonesMap[src.Zone] = struct{}{}
+		namespace := strings.ReplaceAll(src.Zone, ".", "-") + "."
 		namespaces = append(namespaces, namespace)
 	}
 	namespaces = append(namespaces, specialNamespaces...)
@@ -35,15 +55,36 @@ func NewHandler(dns *dns.Client, bags *proxy.BagProvider, rldp *proxy.RLDPConnec
 		bags:       bags,
 		rldp:       rldp,
 		sites:      sites,
+		links:      links,
+		pages:      pages,
+		favicons:   favicons,
+		clearnet:   clearnet,
+		audits:     audits,
+		snapshots:  snapshots,
+		hosting:    hosting,
+		probes:     probes,
+		history:    history,
+		crawler:    crawlerState,
+		ranker:     ranker,
+		suggests:   ttlcache.New[string, []suggestionResponse](suggestTTL, maxSuggestCache),
+		stats:      ttlcache.New[string, cachedJson](statsTTL, maxStatsCache),
 		zones:      zonesMap,
+		sources:    sources,
 		namespaces: namespaces,
 	}
 }
 
 func (h *Handler) ApiHandler(mux *http.ServeMux) http.Handler {
-	mux.HandleFunc("GET /sites/stats", h.GetStats)
-	mux.HandleFunc("GET /sites/random", h.GetRandomSite)
-	mux.HandleFunc("GET /sites", h.GetSites)
+	routes := h.routes()
+	for _, rt := range routes {
+		mux.HandleFunc("GET "+rt.path, rt.validated())
+	}
+	spec, err := newCachedJson(buildOpenApi(routes))
+	if err != nil {
+		panic(fmt.Sprintf("unable to encode openapi document: %v", err))
+	}
+	h.openApi = spec
+	mux.HandleFunc("GET /openapi.json", h.GetOpenApi)
 	return corsMiddleware(mux)
 }
 
@@ -56,3 +97,42 @@ func writeJson(w http.ResponseWriter, response any) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// cachedJson is an encoded response served with an etag, so clients polling it can revalidate for free
+type cachedJson struct {
+	body []byte
+	etag string
+}
+
+func newCachedJson(response any) (cachedJson, error) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return cachedJson{}, err
+	}
+	hash := sha256.Sum256(body)
+	return cachedJson{
+		body: body,
+		etag: fmt.Sprintf("\"%x\"", hash[:16]),
+	}, nil
+}
+
+func writeCachedJson(w http.ResponseWriter, r *http.Request, c cachedJson) {
+	w.Header().Set("Etag", c.etag)
+	if r.Header.Get("If-None-Match") == c.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(c.body)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJsonError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: message})
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/oxylume/index/internal/api"
+)
+
+const (
+	defaultHistoryRange = 30 * 24 * time.Hour
+	defaultHistoryStep  = 24 * time.Hour
+	minHistoryStep      = time.Minute
+	maxHistoryPoints    = 1000
+)
+
+type getStatsHistoryResponse struct {
+	Step   int64                `json:"step"`
+	Points []statsPointResponse `json:"points"`
+}
+
+type statsPointResponse struct {
+	Utime   int64 `json:"utime"`
+	Domains int   `json:"domains"`
+	Sites   int   `json:"sites"`
+	Active  int   `json:"active"`
+	Storage int   `json:"storage"`
+	Adnl    int   `json:"adnl"`
+	Spam    int   `json:"spam"`
+}
+
+func (h *Handler) GetStatsHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	zone := query.Get("zone")
+	to := time.Now()
+	if v, ok, err := api.GetInt(query, "to"); err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if ok {
+		to = time.Unix(int64(v), 0)
+	}
+	from := to.Add(-defaultHistoryRange)
+	if v, ok, err := api.GetInt(query, "from"); err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if ok {
+		from = time.Unix(int64(v), 0)
+	}
+	step := defaultHistoryStep
+	if v, ok, err := api.GetInt(query, "step"); err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if ok {
+		step = time.Duration(v) * time.Second
+	}
+	if !from.Before(to) {
+		writeJsonError(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+	if to.Sub(from)/step > maxHistoryPoints {
+		writeJsonError(w, http.StatusBadRequest, fmt.Sprintf("too many points, range must be at most %d steps", maxHistoryPoints))
+		return
+	}
+
+	points, err := h.history.Series(r.Context(), zone, from, to, step)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respPoints := make([]statsPointResponse, len(points))
+	for i, p := range points {
+		respPoints[i] = statsPointResponse{
+			Utime:   p.At.Unix(),
+			Domains: p.Domains,
+			Sites:   p.Sites,
+			Active:  p.Active,
+			Storage: p.Storage,
+			Adnl:    p.Sites - p.Storage,
+			Spam:    p.Spam,
+		}
+	}
+	writeJson(w, getStatsHistoryResponse{
+		Step:   int64(step.Seconds()),
+		Points: respPoints,
+	})
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+)
+
+const hostingStatsLimit = 20
+
+type getSiteHostingResponse struct {
+	Hosts  []hostResponse `json:"hosts"`
+	Shared int            `json:"shared"`
+}
+
+type hostResponse struct {
+	IP      string `json:"ip"`
+	Port    int    `json:"port"`
+	Country string `json:"country"`
+	Asn     int    `json:"asn"`
+	Org     string `json:"org"`
+}
+
+type getHostingStatsResponse struct {
+	Sites       int                  `json:"sites"`
+	Countries   map[string]int       `json:"countries"`
+	Asns        []asnCountResponse   `json:"asns"`
+	SharedHosts []sharedHostResponse `json:"sharedHosts"`
+	Ipv4        ipv4StatsResponse    `json:"ipv4"`
+}
+
+type asnCountResponse struct {
+	Asn   int    `json:"asn"`
+	Org   string `json:"org"`
+	Sites int    `json:"sites"`
+}
+
+type sharedHostResponse struct {
+	IP    string `json:"ip"`
+	Sites int    `json:"sites"`
+}
+
+type ipv4StatsResponse struct {
+	Addresses int `json:"addresses"`
+	Sites     int `json:"sites"`
+	Shared    int `json:"shared"`
+}
+
+func (h *Handler) GetSiteHosting(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	hosts, err := h.hosting.List(r.Context(), domain)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	shared, err := h.hosting.CountShared(r.Context(), domain)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respHosts := make([]hostResponse, len(hosts))
+	for i, host := range hosts {
+		respHosts[i] = hostResponse{
+			IP:      host.IP.String(),
+			Port:    host.Port,
+			Country: host.Country,
+			Asn:     host.Asn,
+			Org:     host.Org,
+		}
+	}
+	writeJson(w, getSiteHostingResponse{
+		Hosts:  respHosts,
+		Shared: shared,
+	})
+}
+
+func (h *Handler) GetHostingStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.hosting.GetStats(r.Context(), hostingStatsLimit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	resp := getHostingStatsResponse{
+		Sites:       stats.Sites,
+		Countries:   stats.Countries,
+		Asns:        make([]asnCountResponse, len(stats.Asns)),
+		SharedHosts: make([]sharedHostResponse, len(stats.SharedHosts)),
+		Ipv4: ipv4StatsResponse{
+			Addresses: stats.Ipv4Addresses,
+			Sites:     stats.Ipv4Sites,
+			Shared:    stats.Ipv4Shared,
+		},
+	}
+	for i, asn := range stats.Asns {
+		resp.Asns[i] = asnCountResponse{
+			Asn:   asn.Asn,
+			Org:   asn.Org,
+			Sites: asn.Sites,
+		}
+	}
+	for i, host := range stats.SharedHosts {
+		resp.SharedHosts[i] = sharedHostResponse{
+			IP:    host.IP.String(),
+			Sites: host.Sites,
+		}
+	}
+	writeJson(w, resp)
+}
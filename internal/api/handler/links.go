@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+)
+
+type getLinksResponse struct {
+	Links  []string `json:"links"`
+	Cursor string   `json:"cursor,omitempty"`
+}
+
+func (h *Handler) GetBacklinks(w http.ResponseWriter, r *http.Request) {
+	h.listLinks(w, r, h.links.ListBacklinks)
+}
+
+func (h *Handler) GetOutlinks(w http.ResponseWriter, r *http.Request) {
+	h.listLinks(w, r, h.links.ListOutlinks)
+}
+
+type listLinksFunc func(ctx context.Context, domain string, after string, limit int) ([]string, error)
+
+func (h *Handler) listLinks(w http.ResponseWriter, r *http.Request, list listLinksFunc) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := r.URL.Query()
+	after, limit, err := parseKeyPage(query)
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	links, err := list(r.Context(), domain, after, limit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	var respCursor string
+	if len(links) == limit && limit > 0 {
+		respCursor = api.EncodeCursor(&db.Cursor{Domain: links[len(links)-1]})
+	}
+	writeJson(w, getLinksResponse{
+		Links:  links,
+		Cursor: respCursor,
+	})
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+const openApiVersion = "3.0.3"
+
+type openApiDoc struct {
+	OpenApi    string                          `json:"openapi"`
+	Info       openApiInfo                     `json:"info"`
+	Paths      map[string]map[string]operation `json:"paths"`
+	Components openApiComponents               `json:"components"`
+}
+
+type openApiInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openApiComponents struct {
+	Schemas map[string]*jsonSchema `json:"schemas"`
+}
+
+type operation struct {
+	OperationId string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Parameters  []parameter         `json:"parameters,omitempty"`
+	Responses   map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      *jsonSchema `json:"schema"`
+}
+
+type response struct {
+	Description string           `json:"description"`
+	Content     map[string]media `json:"content,omitempty"`
+}
+
+type media struct {
+	Schema *jsonSchema `json:"schema"`
+}
+
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	AllOf                []*jsonSchema          `json:"allOf,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Maximum              *int                   `json:"maximum,omitempty"`
+	MaxLength            int                    `json:"maxLength,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+}
+
+func (h *Handler) GetOpenApi(w http.ResponseWriter, r *http.Request) {
+	writeCachedJson(w, r, h.openApi)
+}
+
+// buildOpenApi derives the document from the route declarations and response types, so it can't drift from the handlers
+func buildOpenApi(routes []route) *openApiDoc {
+	schemas := make(map[string]*jsonSchema)
+	errorSchema := schemaOf(reflect.TypeOf(errorResponse{}), schemas)
+	doc := &openApiDoc{
+		OpenApi:    openApiVersion,
+		Info:       openApiInfo{Title: "oxylume index", Version: "1"},
+		Paths:      make(map[string]map[string]operation, len(routes)),
+		Components: openApiComponents{Schemas: schemas},
+	}
+	for _, rt := range routes {
+		ok := response{Description: "OK"}
+		if rt.response != nil {
+			ok.Content = map[string]media{"application/json": {Schema: schemaOf(reflect.TypeOf(rt.response), schemas)}}
+		} else {
+			ok.Content = map[string]media{rt.contentType: {Schema: &jsonSchema{Type: "string", Format: "binary"}}}
+		}
+		op := operation{
+			OperationId: rt.operation,
+			Summary:     rt.summary,
+			Responses: map[string]response{
+				"200": ok,
+				"default": {
+					Description: "error",
+					Content:     map[string]media{"application/json": {Schema: errorSchema}},
+				},
+			},
+		}
+		for _, p := range rt.params {
+			op.Parameters = append(op.Parameters, p.toOpenApi())
+		}
+		doc.Paths[rt.path] = map[string]operation{"get": op}
+	}
+	return doc
+}
+
+func (p param) toOpenApi() parameter {
+	in := "query"
+	if p.path {
+		in = "path"
+	}
+	schema := &jsonSchema{
+		Type:      string(p.kind),
+		Enum:      p.enum,
+		MaxLength: p.maxLength,
+	}
+	if p.bounded {
+		schema.Minimum = &p.min
+		schema.Maximum = &p.max
+	}
+	return parameter{
+		Name:        p.name,
+		In:          in,
+		Description: p.description,
+		Required:    p.required,
+		Schema:      schema,
+	}
+}
+
+// schemaOf returns the schema of the type as encoded by encoding/json, structs are put in the components and referenced
+func schemaOf(t reflect.Type, schemas map[string]*jsonSchema) *jsonSchema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := schemaOf(t.Elem(), schemas)
+		if schema.Ref != "" {
+			// siblings of $ref are ignored, so nullable has to wrap it
+			return &jsonSchema{AllOf: []*jsonSchema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &jsonSchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &jsonSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &jsonSchema{Type: "string", Format: "byte"}
+		}
+		return &jsonSchema{Type: "array", Items: schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := schemaName(t)
+		ref := &jsonSchema{Ref: "#/components/schemas/" + name}
+		if _, ok := schemas[name]; ok {
+			return ref
+		}
+		schema := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+		// registered before the fields so recursive types terminate
+		schemas[name] = schema
+		addFields(t, schema, schemas)
+		return ref
+	}
+	return &jsonSchema{}
+}
+
+func addFields(t reflect.Type, schema *jsonSchema, schemas map[string]*jsonSchema) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addFields(field.Type, schema, schemas)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = schemaOf(field.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// schemaName strips the Response suffix from the type name, e.g. siteResponse is Site
+func schemaName(t reflect.Type) string {
+	name := strings.TrimSuffix(t.Name(), "Response")
+	if name == "" {
+		name = t.Name()
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+)
+
+type getPagesResponse struct {
+	Pages  []pageResponse `json:"pages"`
+	Cursor string         `json:"cursor,omitempty"`
+}
+
+type pageResponse struct {
+	Path         string `json:"path"`
+	Status       int    `json:"status"`
+	Title        string `json:"title"`
+	FetchedUtime int64  `json:"fetchedUtime"`
+}
+
+type getSitemapResponse struct {
+	Urls   []sitemapUrlResponse `json:"urls"`
+	Cursor string               `json:"cursor,omitempty"`
+}
+
+type sitemapUrlResponse struct {
+	Path         string `json:"path"`
+	LastModUtime *int64 `json:"lastmodUtime"`
+	ChangedUtime int64  `json:"changedUtime"`
+}
+
+func (h *Handler) GetPages(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := r.URL.Query()
+	after, limit, err := parseKeyPage(query)
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pages, err := h.pages.List(r.Context(), domain, after, limit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respPages := make([]pageResponse, len(pages))
+	for i, page := range pages {
+		respPages[i] = pageResponse{
+			Path:         page.Path,
+			Status:       page.Status,
+			Title:        page.Title,
+			FetchedUtime: page.FetchedAt.Unix(),
+		}
+	}
+	var respCursor string
+	if len(pages) == limit && limit > 0 {
+		respCursor = api.EncodeCursor(&db.Cursor{Domain: pages[len(pages)-1].Path})
+	}
+	writeJson(w, getPagesResponse{
+		Pages:  respPages,
+		Cursor: respCursor,
+	})
+}
+
+func (h *Handler) GetSitemap(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	after, limit, err := parseKeyPage(r.URL.Query())
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.pages.ListSitemap(r.Context(), domain, after, limit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respUrls := make([]sitemapUrlResponse, len(entries))
+	for i, entry := range entries {
+		respUrls[i] = sitemapUrlResponse{
+			Path:         entry.Path,
+			ChangedUtime: entry.ChangedAt.Unix(),
+		}
+		if entry.LastMod != nil {
+			lastmod := entry.LastMod.Unix()
+			respUrls[i].LastModUtime = &lastmod
+		}
+	}
+	var respCursor string
+	if len(entries) == limit && limit > 0 {
+		respCursor = api.EncodeCursor(&db.Cursor{Domain: entries[len(entries)-1].Path})
+	}
+	writeJson(w, getSitemapResponse{
+		Urls:   respUrls,
+		Cursor: respCursor,
+	})
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+)
+
+type getProbesResponse struct {
+	Probes []probeResponse `json:"probes"`
+}
+
+type probeResponse struct {
+	Name         string            `json:"name"`
+	Findings     []findingResponse `json:"findings"`
+	Error        string            `json:"error"`
+	DurationMs   int64             `json:"durationMs"`
+	CheckedUtime int64             `json:"checkedUtime"`
+}
+
+type findingResponse struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	Value    any    `json:"value"`
+}
+
+func (h *Handler) GetProbes(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	results, err := h.probes.List(r.Context(), domain)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	writeJson(w, getProbesResponse{Probes: probesToResponse(results)})
+}
+
+func probesToResponse(results []db.ProbeResult) []probeResponse {
+	resp := make([]probeResponse, len(results))
+	for i, res := range results {
+		findings := make([]findingResponse, len(res.Findings))
+		for j, finding := range res.Findings {
+			findings[j] = findingResponse{
+				Kind:     finding.Kind,
+				Severity: string(finding.Severity),
+				Value:    finding.Value,
+			}
+		}
+		resp[i] = probeResponse{
+			Name:         res.Probe,
+			Findings:     findings,
+			Error:        res.Error,
+			DurationMs:   res.Duration.Milliseconds(),
+			CheckedUtime: res.CheckedAt.Unix(),
+		}
+	}
+	return resp
+}
@@ -0,0 +1,368 @@
+package handler
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+// route describes an API endpoint. the OpenAPI document and validation of request parameters are derived from it
+type route struct {
+	path      string
+	operation string
+	summary   string
+	params    []param
+	// response is a value of the json response type, contentType is used instead for the other responses
+	response    any
+	contentType string
+	handler     http.HandlerFunc
+}
+
+type paramKind string
+
+const (
+	paramString  paramKind = "string"
+	paramInteger paramKind = "integer"
+	paramBoolean paramKind = "boolean"
+)
+
+type param struct {
+	name        string
+	path        bool
+	kind        paramKind
+	description string
+	required    bool
+	enum        []string
+	bounded     bool
+	min, max    int
+	maxLength   int
+}
+
+func stringParam(name string, description string) param {
+	return param{name: name, kind: paramString, description: description}
+}
+
+func enumParam(name string, description string, values ...string) param {
+	return param{name: name, kind: paramString, description: description, enum: values}
+}
+
+func intParam(name string, description string) param {
+	return param{name: name, kind: paramInteger, description: description}
+}
+
+func rangeParam(name string, description string, min int, max int) param {
+	return param{name: name, kind: paramInteger, description: description, bounded: true, min: min, max: max}
+}
+
+func boolParam(name string, description string) param {
+	return param{name: name, kind: paramBoolean, description: description}
+}
+
+// pathParam declares a segment of the route path, such parameters are always required
+func pathParam(p param) param {
+	p.path = true
+	p.required = true
+	return p
+}
+
+func requiredParam(p param) param {
+	p.required = true
+	return p
+}
+
+var (
+	domainParam = pathParam(stringParam("domain", "domain in punycode or unicode form"))
+	cursorParam = stringParam("cursor", "opaque cursor to list the next batch")
+	limitParam  = rangeParam("limit", fmt.Sprintf("maximum number of items to return, %d by default", defaultLimit), 0, maxLimit)
+	pageParams  = []param{cursorParam, limitParam}
+)
+
+func (p param) value(r *http.Request) string {
+	if p.path {
+		return r.PathValue(p.name)
+	}
+	return r.URL.Query().Get(p.name)
+}
+
+func (p param) validate(r *http.Request) error {
+	v := p.value(r)
+	if v == "" {
+		if p.required {
+			return fmt.Errorf("missing required parameter %s", p.name)
+		}
+		return nil
+	}
+	switch p.kind {
+	case paramInteger:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q, must be an integer", p.name, v)
+		}
+		if p.bounded && (n < p.min || n > p.max) {
+			return fmt.Errorf("invalid %s %d, must be between %d and %d", p.name, n, p.min, p.max)
+		}
+	case paramBoolean:
+		if v != "true" && v != "false" && v != "1" && v != "0" {
+			return fmt.Errorf("invalid %s %q, must be true or false", p.name, v)
+		}
+	case paramString:
+		if p.maxLength > 0 && utf8.RuneCountInString(v) > p.maxLength {
+			return fmt.Errorf("invalid %s, must not be longer than %d characters", p.name, p.maxLength)
+		}
+		if p.enum != nil && !slices.Contains(p.enum, v) {
+			return fmt.Errorf("invalid %s %q, must be one of %v", p.name, v, p.enum)
+		}
+	}
+	return nil
+}
+
+// validated rejects requests with invalid parameters before they reach the handler
+func (rt route) validated() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range rt.params {
+			if err := p.validate(r); err != nil {
+				writeJsonError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		rt.handler(w, r)
+	}
+}
+
+func (h *Handler) routes() []route {
+	zones := slices.Sorted(maps.Keys(h.zones))
+	zoneParam := enumParam("zone", "domain zone defined by DOMAIN_SOURCES", zones...)
+	sortValues := make([]string, 0, len(allowedSortBy))
+	for sortBy := range allowedSortBy {
+		sortValues = append(sortValues, string(sortBy))
+	}
+	slices.Sort(sortValues)
+	redirectValues := make([]string, 0, len(redirectKinds))
+	for kind := db.RedirectNone; kind <= db.RedirectClearnet; kind++ {
+		redirectValues = append(redirectValues, redirectKinds[kind])
+	}
+	q := requiredParam(stringParam("q", "search prefix"))
+	q.maxLength = maxSuggestQuery
+	siteId := pathParam(stringParam("id", "ADNL address in user-friendly or hex form"))
+	bagId := pathParam(stringParam("id", "bag id in hex form"))
+
+	return []route{
+		{
+			path:      "/sites/stats",
+			operation: "getStats",
+			summary:   "Get statistics about indexed sites",
+			params:    []param{zoneParam},
+			response:  getStatsResponse{},
+			handler:   h.GetStats,
+		},
+		{
+			path:      "/sites/stats/history",
+			operation: "getStatsHistory",
+			summary:   "Get statistics over time downsampled to one point per step",
+			params: []param{
+				intParam("from", "unix time of the series start, 30 days before to by default"),
+				intParam("to", "unix time of the series end (exclusive), now by default"),
+				rangeParam("step", "seconds per point, a day by default", int(minHistoryStep.Seconds()), 1<<31-1),
+				zoneParam,
+			},
+			response: getStatsHistoryResponse{},
+			handler:  h.GetStatsHistory,
+		},
+		{
+			path:      "/sites/random",
+			operation: "getRandomSite",
+			summary:   "Get a random accessible site",
+			params:    []param{boolParam("weighted", "pick sites with a higher rank more often")},
+			response:  siteResponse{},
+			handler:   h.GetRandomSite,
+		},
+		{
+			path:      "/sites/suggest",
+			operation: "getSuggestions",
+			summary:   "Autocomplete accessible non-spam sites by domain or title prefix",
+			params:    []param{q, rangeParam("limit", fmt.Sprintf("maximum number of suggestions, %d by default", defaultSuggest), 1, maxSuggest)},
+			response:  getSuggestionsResponse{},
+			handler:   h.GetSuggestions,
+		},
+		{
+			path:      "/search/syntax",
+			operation: "getSearchSyntax",
+			summary:   "Get the grammar and keys of search expressions",
+			response:  getSearchSyntaxResponse{},
+			handler:   h.GetSearchSyntax,
+		},
+		{
+			path:      "/sites",
+			operation: "getSites",
+			summary:   "List filtered indexed sites",
+			params: append([]param{
+				stringParam("search", "search expression, its keys take precedence over the individual parameters"),
+				boolParam("inaccessible", "include inaccessible sites"),
+				boolParam("punycode", "show only (true) or exclude (false) punycode domains"),
+				boolParam("spam", "include sites with a potentially spam content"),
+				zoneParam,
+				stringParam("lang", "ISO 639-1 code of the language, e.g. en"),
+				stringParam("category", "category defined by the taxonomy"),
+				stringParam("tech", "detected technology"),
+				boolParam("storage", "show only sites hosted in TON storage (true) or over ADNL (false)"),
+				boolParam("selfContained", "show only sites that load nothing from the clearnet (true) or only ones that do (false)"),
+				enumParam("redirect", "redirect of the main page", redirectValues...),
+				enumParam("sort", "sort field, domain by default", sortValues...),
+				boolParam("desc", "sort in descending order"),
+			}, pageParams...),
+			response: getSitesResponse{},
+			handler:  h.GetSites,
+		},
+		{
+			path:      "/sites/{domain}",
+			operation: "getSite",
+			summary:   "Get the full record of the domain",
+			params:    []param{domainParam},
+			response:  siteDetailResponse{},
+			handler:   h.GetSite,
+		},
+		{
+			path:      "/sites/{domain}/backlinks",
+			operation: "getBacklinks",
+			summary:   "List sites that link to the domain",
+			params:    append([]param{domainParam}, pageParams...),
+			response:  getLinksResponse{},
+			handler:   h.GetBacklinks,
+		},
+		{
+			path:      "/sites/{domain}/outlinks",
+			operation: "getOutlinks",
+			summary:   "List sites the domain links to",
+			params:    append([]param{domainParam}, pageParams...),
+			response:  getLinksResponse{},
+			handler:   h.GetOutlinks,
+		},
+		{
+			path:      "/sites/{domain}/pages",
+			operation: "getPages",
+			summary:   "List pages of the site found by the spider",
+			params:    append([]param{domainParam}, pageParams...),
+			response:  getPagesResponse{},
+			handler:   h.GetPages,
+		},
+		{
+			path:      "/sites/{domain}/sitemap",
+			operation: "getSitemap",
+			summary:   "List page urls published in the sitemap of the site",
+			params:    append([]param{domainParam}, pageParams...),
+			response:  getSitemapResponse{},
+			handler:   h.GetSitemap,
+		},
+		{
+			path:        "/sites/{domain}/favicon",
+			operation:   "getFavicon",
+			summary:     "Get the favicon of the site",
+			params:      []param{domainParam},
+			contentType: "image/*",
+			handler:     h.GetFavicon,
+		},
+		{
+			path:      "/sites/{domain}/clearnet",
+			operation: "getClearnetDeps",
+			summary:   "List resources the site loads from the clearnet",
+			params:    append([]param{domainParam}, pageParams...),
+			response:  getClearnetDepsResponse{},
+			handler:   h.GetClearnetDeps,
+		},
+		{
+			path:      "/sites/{domain}/audit",
+			operation: "getAudit",
+			summary:   "List broken internal links and missing assets of the site",
+			params:    append([]param{domainParam}, pageParams...),
+			response:  getAuditResponse{},
+			handler:   h.GetAudit,
+		},
+		{
+			path:      "/sites/{domain}/snapshots",
+			operation: "getSnapshots",
+			summary:   "List archived snapshots of the main page, newest first",
+			params:    append([]param{domainParam}, pageParams...),
+			response:  getSnapshotsResponse{},
+			handler:   h.GetSnapshots,
+		},
+		{
+			path:      "/sites/{domain}/snapshots/diff",
+			operation: "getSnapshotsDiff",
+			summary:   "Get a unified diff between two snapshots",
+			params: []param{
+				domainParam,
+				requiredParam(intParam("from", "id of the older snapshot")),
+				requiredParam(intParam("to", "id of the newer snapshot")),
+			},
+			response: getSnapshotsDiffResponse{},
+			handler:  h.GetSnapshotsDiff,
+		},
+		{
+			path:        "/sites/{domain}/snapshots/{id}",
+			operation:   "getSnapshot",
+			summary:     "Get the archived main page as text",
+			params:      []param{domainParam, pathParam(intParam("id", "snapshot id"))},
+			contentType: "text/plain",
+			handler:     h.GetSnapshot,
+		},
+		{
+			path:      "/sites/{domain}/hosting",
+			operation: "getSiteHosting",
+			summary:   "Get addresses the ADNL site advertised in DHT",
+			params:    []param{domainParam},
+			response:  getSiteHostingResponse{},
+			handler:   h.GetSiteHosting,
+		},
+		{
+			path:      "/sites/{domain}/probes",
+			operation: "getProbes",
+			summary:   "Get results of the probes run during the last check",
+			params:    []param{domainParam},
+			response:  getProbesResponse{},
+			handler:   h.GetProbes,
+		},
+		{
+			path:      "/hosting/stats",
+			operation: "getHostingStats",
+			summary:   "Get hosting distribution of accessible ADNL sites",
+			response:  getHostingStatsResponse{},
+			handler:   h.GetHostingStats,
+		},
+		{
+			path:      "/zones",
+			operation: "getZones",
+			summary:   "List configured domain zones",
+			response:  getZonesResponse{},
+			handler:   h.GetZones,
+		},
+		{
+			path:      "/adnl/{id}",
+			operation: "getAdnlSites",
+			summary:   "List sites pointing to the ADNL address",
+			params:    append([]param{siteId}, pageParams...),
+			response:  getSitesResponse{},
+			handler:   h.GetAdnlSites,
+		},
+		{
+			path:      "/bags/{id}",
+			operation: "getBagSites",
+			summary:   "List sites pointing to the bag",
+			params:    append([]param{bagId}, pageParams...),
+			response:  getSitesResponse{},
+			handler:   h.GetBagSites,
+		},
+		{
+			path:      "/wallets/{address}/domains",
+			operation: "getWalletDomains",
+			summary:   "List domains which wallet record points to the address",
+			params:    append([]param{pathParam(stringParam("address", "address in user-friendly or raw form"))}, pageParams...),
+			response:  getSitesResponse{},
+			handler:   h.GetWalletDomains,
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+)
+
+type getSearchSyntaxResponse struct {
+	Grammar  string          `json:"grammar"`
+	Keys     []api.SearchKey `json:"keys"`
+	Examples []string        `json:"examples"`
+}
+
+var searchExamples = []string{
+	`zone:.t.me storage:true lang:en "nft market" -spam`,
+	`category:game -storage`,
+	`wallet punycode:false`,
+}
+
+func (h *Handler) GetSearchSyntax(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, getSearchSyntaxResponse{
+		Grammar:  api.SearchGrammar,
+		Keys:     api.SearchKeys,
+		Examples: searchExamples,
+	})
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+)
+
+func (h *Handler) GetAdnlSites(w http.ResponseWriter, r *http.Request) {
+	h.getSitesById(w, r, false)
+}
+
+func (h *Handler) GetBagSites(w http.ResponseWriter, r *http.Request) {
+	h.getSitesById(w, r, true)
+}
+
+// getSitesById lists domains which "TON Site" record points to the ADNL address or the bag
+func (h *Handler) getSitesById(w http.ResponseWriter, r *http.Request, inStorage bool) {
+	id, err := api.ParseSiteId(r.PathValue("id"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, fmt.Sprintf("invalid id: %v", err))
+		return
+	}
+	after, limit, err := parseKeyPage(r.URL.Query())
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sites, err := h.sites.ListBySiteId(r.Context(), id, inStorage, after, limit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respSites := make([]siteResponse, len(sites))
+	for i, item := range sites {
+		respSites[i] = siteToResponse(item)
+	}
+	var respCursor string
+	if len(sites) == limit && limit > 0 {
+		respCursor = api.EncodeCursor(&db.Cursor{Domain: sites[len(sites)-1].Domain})
+	}
+	writeJson(w, getSitesResponse{
+		Sites:  respSites,
+		Cursor: respCursor,
+	})
+}
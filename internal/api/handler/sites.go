@@ -1,21 +1,30 @@
 package handler
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/oxylume/index/internal/api"
 	"github.com/oxylume/index/internal/db"
 )
 
 const (
-	maxLimit = 1000
+	defaultLimit  = 50
+	maxLimit      = 1000
+	statsTTL      = 30 * time.Second
+	maxStatsCache = 64
 )
 
 type getStatsResponse struct {
-	Domains int `json:"domains"`
-	Sites   int `json:"sites"`
-	Active  int `json:"active"`
+	Domains int            `json:"domains"`
+	Sites   int            `json:"sites"`
+	Active  int            `json:"active"`
+	Tech    map[string]int `json:"tech"`
 }
 
 type getSitesResponse struct {
@@ -24,38 +33,116 @@ type getSitesResponse struct {
 }
 
 type siteResponse struct {
-	Domain       string `json:"domain"`
-	Unicode      string `json:"unicode"`
-	Address      string `json:"address"`
-	Accessible   bool   `json:"accessible"`
-	InStorage    bool   `json:"inStorage"`
-	SpamContent  bool   `json:"spamContent"`
-	CheckedUtime int64  `json:"checkedUtime"`
+	Domain       string             `json:"domain"`
+	Unicode      string             `json:"unicode"`
+	Address      string             `json:"address"`
+	Accessible   bool               `json:"accessible"`
+	InStorage    bool               `json:"inStorage"`
+	SpamContent  bool               `json:"spamContent"`
+	CheckedUtime int64              `json:"checkedUtime"`
+	Backlinks    int                `json:"backlinks"`
+	Robots       string             `json:"robots"`
+	Lang         string             `json:"lang"`
+	Categories   []string           `json:"categories"`
+	Rank         float64            `json:"rank"`
+	Title        string             `json:"title"`
+	Server       string             `json:"server"`
+	PoweredBy    string             `json:"poweredBy"`
+	Tech         []string           `json:"tech"`
+	ClearnetDeps int                `json:"clearnetDeps"`
+	Redirect     string             `json:"redirect"`
+	RedirectTo   string             `json:"redirectTo"`
+	ChangedUtime int64              `json:"changedUtime"`
+	Bag          *bagHealthResponse `json:"bag"`
+	SiteId       string             `json:"siteId"`
+	Wallet       string             `json:"wallet"`
+}
+
+type siteDetailResponse struct {
+	siteResponse
+	Status             string          `json:"status"`
+	Zone               string          `json:"zone"`
+	CreatedUtime       int64           `json:"createdUtime"`
+	CheckingUntilUtime int64           `json:"checkingUntilUtime"`
+	Uptime             float64         `json:"uptime"`
+	LatencyMs          *int64          `json:"latencyMs"`
+	ContentSize        int             `json:"contentSize"`
+	GatewayHits        int64           `json:"gatewayHits"`
+	Spam               spamResponse    `json:"spam"`
+	Probes             []probeResponse `json:"probes"`
+}
+
+type spamResponse struct {
+	Content bool     `json:"content"`
+	Rules   []string `json:"rules"`
+}
+
+type bagHealthResponse struct {
+	Peers     int     `json:"peers"`
+	Size      int64   `json:"size"`
+	Files     int     `json:"files"`
+	Available float64 `json:"available"`
+	Score     int     `json:"score"`
+}
+
+var robotsPolicies = map[db.RobotsPolicy]string{
+	db.RobotsNone:       "none",
+	db.RobotsAllowed:    "allowed",
+	db.RobotsPartial:    "partial",
+	db.RobotsDisallowed: "disallowed",
+}
+
+var siteStatuses = map[db.SiteStatus]string{
+	db.StatusNoSite:       "noSite",
+	db.StatusInaccessible: "inaccessible",
+	db.StatusAccessible:   "accessible",
+}
+
+var redirectKinds = map[db.RedirectKind]string{
+	db.RedirectNone:     "none",
+	db.RedirectSite:     "site",
+	db.RedirectTon:      "ton",
+	db.RedirectClearnet: "clearnet",
 }
 
 var allowedSortBy = map[db.SortBy]struct{}{
 	db.SortByDomain:    {},
 	db.SortByCheckedAt: {},
+	db.SortByBacklinks: {},
+	db.SortByRank:      {},
+	db.SortByChangedAt: {},
 }
 
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.sites.GetStats(r.Context())
-	if err != nil {
-		http.Error(w, fmt.Sprintf("internal error: %v", err), http.StatusInternalServerError)
-		return
-	}
-	resp := getStatsResponse{
-		Domains: stats.TotalDomains,
-		Sites:   stats.TotalSites,
-		Active:  stats.ActiveSites,
+	zone := r.URL.Query().Get("zone")
+	resp, ok := h.stats.Get(zone)
+	if !ok {
+		stats, err := h.sites.GetStats(r.Context(), zone)
+		if err != nil {
+			writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+			return
+		}
+		resp, err = newCachedJson(getStatsResponse{
+			Domains: stats.TotalDomains,
+			Sites:   stats.TotalSites,
+			Active:  stats.ActiveSites,
+			Tech:    stats.Tech,
+		})
+		if err != nil {
+			writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+			return
+		}
+		h.stats.Set(zone, resp)
 	}
-	writeJson(w, resp)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(statsTTL.Seconds())))
+	writeCachedJson(w, r, resp)
 }
 
 func (h *Handler) GetRandomSite(w http.ResponseWriter, r *http.Request) {
-	site, err := h.sites.GetRandomSite(r.Context())
+	weighted, _ := api.GetBool(r.URL.Query(), "weighted")
+	site, err := h.sites.GetRandomSite(r.Context(), weighted)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("internal error: %v", err), http.StatusInternalServerError)
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
 		return
 	}
 	writeJson(w, siteToResponse(*site))
@@ -64,7 +151,6 @@ func (h *Handler) GetRandomSite(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetSites(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	var params db.ListFilters
-	params.Search = query.Get("search")
 	if v, ok := api.GetBool(query, "inaccessible"); ok {
 		params.Inaccessible = v
 	}
@@ -74,19 +160,39 @@ func (h *Handler) GetSites(w http.ResponseWriter, r *http.Request) {
 	if v, ok := api.GetBool(query, "spam"); ok {
 		params.Spam = v
 	}
-	if v := query.Get("zone"); v != "" {
-		if _, ok := h.zones[v]; !ok {
-			http.Error(w, fmt.Sprintf("invalid zone %s", v), http.StatusBadRequest)
+	params.Zone = query.Get("zone")
+	if v := query.Get("lang"); v != "" {
+		if len(v) != 2 {
+			writeJsonError(w, http.StatusBadRequest, fmt.Sprintf("invalid lang %s, must be ISO 639-1 code", v))
 			return
 		}
-		params.Zone = v
+		params.Lang = strings.ToLower(v)
 	}
-	params.SortBy = db.SortByDomain
-	if v := query.Get("sort"); v != "" {
-		if _, ok := allowedSortBy[db.SortBy(v)]; !ok {
-			http.Error(w, fmt.Sprintf("invalid sort value %s", v), http.StatusBadRequest)
+	params.Category = strings.ToLower(query.Get("category"))
+	params.Tech = strings.ToLower(query.Get("tech"))
+	if v, ok := api.GetBool(query, "storage"); ok {
+		params.InStorage = &v
+	}
+	if v, ok := api.GetBool(query, "selfContained"); ok {
+		params.SelfContained = &v
+	}
+	if v := query.Get("redirect"); v != "" {
+		kind := api.RedirectKinds[v]
+		params.Redirect = &kind
+	}
+	// keys of the search expression take precedence over the individual parameters
+	if err := api.ParseSearch(query.Get("search"), &params); err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if params.Zone != "" {
+		if _, ok := h.zones[params.Zone]; !ok {
+			writeJsonError(w, http.StatusBadRequest, fmt.Sprintf("invalid zone %s", params.Zone))
 			return
 		}
+	}
+	params.SortBy = db.SortByDomain
+	if v := query.Get("sort"); v != "" {
 		params.SortBy = db.SortBy(v)
 	}
 	if v, ok := api.GetBool(query, "desc"); ok {
@@ -96,26 +202,20 @@ func (h *Handler) GetSites(w http.ResponseWriter, r *http.Request) {
 	if v := query.Get("cursor"); v != "" {
 		parsed, err := api.DecodeCursor(v, params.SortBy)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("unable to parse cursor: %v", err), http.StatusBadRequest)
+			writeJsonError(w, http.StatusBadRequest, fmt.Sprintf("unable to parse cursor: %v", err))
 			return
 		}
 		cursor = parsed
 	}
-	limit := 50
-	if v, ok, err := api.GetInt(query, "limit"); err != nil {
-		http.Error(w, fmt.Sprintf("unable to parse limit: %v", err), http.StatusBadRequest)
+	limit, err := parseLimit(query)
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
 		return
-	} else if ok {
-		if v < 0 || v > maxLimit {
-			http.Error(w, fmt.Sprintf("limit must be between 0 and %d", maxLimit), http.StatusBadRequest)
-			return
-		}
-		limit = v
 	}
 
 	sites, nextCursor, err := h.sites.List(r.Context(), &params, cursor, limit)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("internal error: %v", err), http.StatusInternalServerError)
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
 		return
 	}
 
@@ -134,7 +234,72 @@ func (h *Handler) GetSites(w http.ResponseWriter, r *http.Request) {
 	writeJson(w, resp)
 }
 
+func (h *Handler) GetSite(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	site, err := h.sites.Get(r.Context(), domain)
+	if errors.Is(err, db.ErrNotFound) {
+		writeJsonError(w, http.StatusNotFound, fmt.Sprintf("domain %s not found", domain))
+		return
+	}
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	probes, err := h.probes.List(r.Context(), domain)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+
+	resp := siteDetailResponse{
+		siteResponse: siteToResponse(site.Site),
+		Status:       siteStatuses[site.Status],
+		Zone:         site.Zone,
+		CreatedUtime: site.CreatedAt.Unix(),
+		Uptime:       site.Uptime,
+		ContentSize:  site.ContentSize,
+		GatewayHits:  site.GatewayHits,
+		Spam: spamResponse{
+			Content: site.SpamContent,
+			Rules:   []string{},
+		},
+		Probes: probesToResponse(probes),
+	}
+	if site.CheckingUntil != nil {
+		resp.CheckingUntilUtime = site.CheckingUntil.Unix()
+	}
+	if site.Latency != nil {
+		ms := site.Latency.Milliseconds()
+		resp.LatencyMs = &ms
+	}
+	for _, probe := range probes {
+		if probe.Probe != "spam" {
+			continue
+		}
+		for _, finding := range probe.Findings {
+			if rule, ok := finding.Value.(string); ok {
+				resp.Spam.Rules = append(resp.Spam.Rules, rule)
+			}
+		}
+	}
+	writeJson(w, resp)
+}
+
 func siteToResponse(site db.Site) siteResponse {
+	var bag *bagHealthResponse
+	if site.Bag != nil {
+		bag = &bagHealthResponse{
+			Peers:     site.Bag.Peers,
+			Size:      site.Bag.Size,
+			Files:     site.Bag.Files,
+			Available: site.Bag.Available,
+			Score:     site.Bag.Score,
+		}
+	}
 	return siteResponse{
 		Domain:       site.Domain,
 		Unicode:      site.Unicode,
@@ -143,5 +308,77 @@ func siteToResponse(site db.Site) siteResponse {
 		InStorage:    site.InStorage,
 		SpamContent:  site.SpamContent,
 		CheckedUtime: site.CheckedAt.Unix(),
+		Backlinks:    site.Backlinks,
+		Robots:       robotsPolicies[site.Robots],
+		Lang:         site.Lang,
+		Categories:   site.Categories,
+		Rank:         site.Rank,
+		Title:        site.Title,
+		Server:       site.Server,
+		PoweredBy:    site.PoweredBy,
+		Tech:         site.Tech,
+		ClearnetDeps: site.ClearnetDeps,
+		Redirect:     redirectKinds[site.Redirect],
+		RedirectTo:   site.RedirectTo,
+		ChangedUtime: site.ContentChangedAt.Unix(),
+		Bag:          bag,
+		SiteId:       encodeSiteId(site.SiteId, site.InStorage),
+		Wallet:       site.Wallet,
+	}
+}
+
+// encodeSiteId returns bag ids in hex and ADNL addresses in their user-friendly form
+func encodeSiteId(id []byte, inStorage bool) string {
+	if len(id) == 0 {
+		return ""
+	}
+	if inStorage {
+		return hex.EncodeToString(id)
+	}
+	return api.EncodeAdnl(id)
+}
+
+// parseIdPage is parseKeyPage for lists ordered by a numeric id, zero means the first page
+func parseIdPage(query url.Values) (before int64, limit int, err error) {
+	if v := query.Get("cursor"); v != "" {
+		parsed, err := api.DecodeIdCursor(v)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unable to parse cursor: %w", err)
+		}
+		before = parsed.Id
+	}
+	limit, err = parseLimit(query)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, limit, nil
+}
+
+func parseLimit(query url.Values) (int, error) {
+	limit := defaultLimit
+	if v, ok, err := api.GetInt(query, "limit"); err != nil {
+		return 0, fmt.Errorf("unable to parse limit: %w", err)
+	} else if ok {
+		if v < 0 || v > maxLimit {
+			return 0, fmt.Errorf("limit must be between 0 and %d", maxLimit)
+		}
+		limit = v
+	}
+	return limit, nil
+}
+
+// parseKeyPage parses pagination of lists ordered by a single text key
+func parseKeyPage(query url.Values) (after string, limit int, err error) {
+	if v := query.Get("cursor"); v != "" {
+		parsed, err := api.DecodeCursor(v, db.SortByDomain)
+		if err != nil {
+			return "", 0, fmt.Errorf("unable to parse cursor: %w", err)
+		}
+		after = parsed.Domain
+	}
+	limit, err = parseLimit(query)
+	if err != nil {
+		return "", 0, err
 	}
+	return after, limit, nil
 }
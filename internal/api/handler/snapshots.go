@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+	"github.com/oxylume/index/pkg/diff"
+)
+
+const diffContext = 3
+
+type getSnapshotsResponse struct {
+	Snapshots []snapshotResponse `json:"snapshots"`
+	Cursor    string             `json:"cursor,omitempty"`
+}
+
+type snapshotResponse struct {
+	Id           int64  `json:"id"`
+	Hash         string `json:"hash"`
+	Size         int    `json:"size"`
+	CreatedUtime int64  `json:"createdUtime"`
+}
+
+type getSnapshotsDiffResponse struct {
+	From    int64  `json:"from"`
+	To      int64  `json:"to"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+	Diff    string `json:"diff"`
+}
+
+func (h *Handler) GetSnapshots(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	before, limit, err := parseIdPage(r.URL.Query())
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snapshots, err := h.snapshots.List(r.Context(), domain, before, limit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respSnapshots := make([]snapshotResponse, len(snapshots))
+	for i, snapshot := range snapshots {
+		respSnapshots[i] = snapshotResponse{
+			Id:           snapshot.Id,
+			Hash:         fmt.Sprintf("%x", snapshot.Hash),
+			Size:         snapshot.Size,
+			CreatedUtime: snapshot.CreatedAt.Unix(),
+		}
+	}
+	var respCursor string
+	if len(snapshots) == limit && limit > 0 {
+		respCursor = api.EncodeCursor(&db.Cursor{Id: snapshots[len(snapshots)-1].Id})
+	}
+	writeJson(w, getSnapshotsResponse{
+		Snapshots: respSnapshots,
+		Cursor:    respCursor,
+	})
+}
+
+func (h *Handler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, fmt.Sprintf("invalid snapshot id %s", r.PathValue("id")))
+		return
+	}
+	snapshot, err := h.snapshots.Get(r.Context(), domain, id)
+	if errors.Is(err, db.ErrNotFound) {
+		writeJsonError(w, http.StatusNotFound, fmt.Sprintf("snapshot %d not found", id))
+		return
+	}
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+
+	// snapshots are immutable
+	w.Header().Set("Etag", fmt.Sprintf("\"%x\"", snapshot.Hash))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	// archived pages are served as text so their scripts never run in our origin
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Length", fmt.Sprint(len(snapshot.Content)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.WriteHeader(http.StatusOK)
+	w.Write(snapshot.Content)
+}
+
+func (h *Handler) GetSnapshotsDiff(w http.ResponseWriter, r *http.Request) {
+	domain, err := api.ParseDomain(r.PathValue("domain"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := r.URL.Query()
+	from, ok, err := api.GetInt(query, "from")
+	if err == nil && !ok {
+		err = fmt.Errorf("missing snapshot id for key from")
+	}
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, ok, err := api.GetInt(query, "to")
+	if err == nil && !ok {
+		err = fmt.Errorf("missing snapshot id for key to")
+	}
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snapshots := make([]*db.Snapshot, 2)
+	for i, id := range []int{from, to} {
+		snapshots[i], err = h.snapshots.Get(r.Context(), domain, int64(id))
+		if errors.Is(err, db.ErrNotFound) {
+			writeJsonError(w, http.StatusNotFound, fmt.Sprintf("snapshot %d not found", id))
+			return
+		}
+		if err != nil {
+			writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+			return
+		}
+	}
+	edits := diff.Diff(diff.Lines(string(snapshots[0].Content)), diff.Lines(string(snapshots[1].Content)))
+	resp := getSnapshotsDiffResponse{
+		From: snapshots[0].Id,
+		To:   snapshots[1].Id,
+		Diff: diff.Unified(strconv.Itoa(from), strconv.Itoa(to), edits, diffContext),
+	}
+	for _, edit := range edits {
+		switch edit.Op {
+		case diff.Insert:
+			resp.Added++
+		case diff.Delete:
+			resp.Removed++
+		}
+	}
+	writeJson(w, resp)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/oxylume/index/internal/api"
+)
+
+const (
+	suggestTTL      = 30 * time.Second
+	maxSuggestCache = 10000
+	defaultSuggest  = 10
+	maxSuggest      = 20
+	maxSuggestQuery = 64
+)
+
+type getSuggestionsResponse struct {
+	Suggestions []suggestionResponse `json:"suggestions"`
+}
+
+type suggestionResponse struct {
+	Domain  string `json:"domain"`
+	Unicode string `json:"unicode"`
+	Title   string `json:"title"`
+}
+
+func (h *Handler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.ToLower(strings.TrimSpace(query.Get("q")))
+	if q == "" {
+		writeJsonError(w, http.StatusBadRequest, "missing q query parameter")
+		return
+	}
+	limit := defaultSuggest
+	if v, ok, _ := api.GetInt(query, "limit"); ok {
+		limit = v
+	}
+
+	key := fmt.Sprintf("%d:%s", limit, q)
+	suggestions, ok := h.suggests.Get(key)
+	if !ok {
+		found, err := h.sites.Suggest(r.Context(), q, limit)
+		if err != nil {
+			writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+			return
+		}
+		suggestions = make([]suggestionResponse, len(found))
+		for i, s := range found {
+			suggestions[i] = suggestionResponse{
+				Domain:  s.Domain,
+				Unicode: s.Unicode,
+				Title:   s.Title,
+			}
+		}
+		h.suggests.Set(key, suggestions)
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(suggestTTL.Seconds())))
+	writeJson(w, getSuggestionsResponse{
+		Suggestions: suggestions,
+	})
+}
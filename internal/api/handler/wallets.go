@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/oxylume/index/internal/api"
+	"github.com/oxylume/index/internal/db"
+)
+
+// GetWalletDomains lists domains which "wallet" DNS record points to the address
+func (h *Handler) GetWalletDomains(w http.ResponseWriter, r *http.Request) {
+	addr, err := api.ParseAddress(r.PathValue("address"))
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, fmt.Sprintf("invalid address: %v", err))
+		return
+	}
+	after, limit, err := parseKeyPage(r.URL.Query())
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sites, err := h.sites.ListByWallet(r.Context(), addr.StringRaw(), after, limit)
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	respSites := make([]siteResponse, len(sites))
+	for i, item := range sites {
+		respSites[i] = siteToResponse(item)
+	}
+	var respCursor string
+	if len(sites) == limit && limit > 0 {
+		respCursor = api.EncodeCursor(&db.Cursor{Domain: sites[len(sites)-1].Domain})
+	}
+	writeJson(w, getSitesResponse{
+		Sites:  respSites,
+		Cursor: respCursor,
+	})
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type getZonesResponse struct {
+	Zones []zoneResponse `json:"zones"`
+}
+
+type zoneResponse struct {
+	Zone    string `json:"zone"`
+	Address string `json:"address"`
+	Offset  int    `json:"offset"`
+	// SyncedUtime is when the crawler last reached the end of the collection, so offset is its size then
+	SyncedUtime *int64 `json:"syncedUtime"`
+	Domains     int    `json:"domains"`
+	Sites       int    `json:"sites"`
+	Active      int    `json:"active"`
+	Storage     int    `json:"storage"`
+	Spam        int    `json:"spam"`
+}
+
+func (h *Handler) GetZones(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.sites.CountZones(r.Context())
+	if err != nil {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+		return
+	}
+	zones := make([]zoneResponse, len(h.sources))
+	for i, src := range h.sources {
+		state, err := h.crawler.GetState(r.Context(), src.Address.StringRaw())
+		if err != nil {
+			writeJsonError(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %v", err))
+			return
+		}
+		count := counts[src.Zone]
+		zones[i] = zoneResponse{
+			Zone:    src.Zone,
+			Address: src.Address.String(),
+			Offset:  state.Offset,
+			Domains: count.Domains,
+			Sites:   count.Sites,
+			Active:  count.Active,
+			Storage: count.Storage,
+			Spam:    count.Spam,
+		}
+		if state.SyncedAt != nil {
+			synced := state.SyncedAt.Unix()
+			zones[i].SyncedUtime = &synced
+		}
+	}
+	writeJson(w, getZonesResponse{Zones: zones})
+}
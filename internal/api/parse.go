@@ -3,12 +3,15 @@ package api
 import (
 	"encoding/base32"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/sigurn/crc16"
+	"github.com/xssnick/tonutils-go/address"
+	"golang.org/x/net/idna"
 )
 
 var crc16table = crc16.MakeTable(crc16.CRC16_XMODEM)
@@ -36,6 +39,51 @@ func ParseAdnl(address string) ([]byte, error) {
 	return decoded[1:33], nil
 }
 
+// EncodeAdnl returns the user-friendly 55 characters form of the ADNL address, the reverse of ParseAdnl
+func EncodeAdnl(id []byte) string {
+	data := make([]byte, 35)
+	data[0] = 0x2d
+	copy(data[1:33], id)
+	binary.BigEndian.PutUint16(data[33:], crc16.Checksum(data[:33], crc16table))
+	return strings.ToLower(base32.StdEncoding.EncodeToString(data)[1:])
+}
+
+// ParseSiteId accepts the ADNL address in either user-friendly or hex form
+func ParseSiteId(id string) ([]byte, error) {
+	if len(id) == 64 {
+		decoded, err := hex.DecodeString(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode id: %w", err)
+		}
+		return decoded, nil
+	}
+	return ParseAdnl(id)
+}
+
+// ParseAddress accepts the address in either user-friendly or raw form
+func ParseAddress(addr string) (*address.Address, error) {
+	parsed, err := address.ParseAddr(addr)
+	if err != nil {
+		if parsed, err = address.ParseRawAddr(addr); err != nil {
+			return nil, err
+		}
+	}
+	return parsed, nil
+}
+
+// ParseDomain accepts domain in either punycode or unicode form and returns its punycode form
+func ParseDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return "", fmt.Errorf("empty domain")
+	}
+	ascii, err := idna.Punycode.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("invalid domain %q: %w", domain, err)
+	}
+	return ascii, nil
+}
+
 func ParseRange(r *http.Request, max uint64) (from uint64, to uint64, hasRange bool, err error) {
 	rangeHeader := r.Header.Get("Range")
 	if !strings.HasPrefix(rangeHeader, "bytes=") {
@@ -33,7 +33,9 @@ func GetInt(q url.Values, key string) (v int, ok bool, err error) {
 
 func EncodeCursor(c *db.Cursor) string {
 	var raw string
-	if c.Value == nil {
+	if c.Id != 0 {
+		raw = strconv.FormatInt(c.Id, 10)
+	} else if c.Value == nil {
 		raw = c.Domain
 	} else {
 		raw = fmt.Sprintf("%v:%s", c.Value, c.Domain)
@@ -41,6 +43,19 @@ func EncodeCursor(c *db.Cursor) string {
 	return base64.URLEncoding.EncodeToString([]byte(raw))
 }
 
+// DecodeIdCursor decodes the cursor of a list ordered by a numeric id
+func DecodeIdCursor(s string) (*db.Cursor, error) {
+	data, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("invalid cursor value %s", data)
+	}
+	return &db.Cursor{Id: id}, nil
+}
+
 func DecodeCursor(s string, sortBy db.SortBy) (*db.Cursor, error) {
 	data, err := base64.URLEncoding.DecodeString(s)
 	if err != nil {
@@ -55,13 +70,25 @@ func DecodeCursor(s string, sortBy db.SortBy) (*db.Cursor, error) {
 		return nil, fmt.Errorf("invalid cursor format %s", raw)
 	}
 	switch sortBy {
-	case db.SortByCheckedAt:
+	case db.SortByCheckedAt, db.SortByChangedAt:
 		secs, err := strconv.ParseInt(v, 10, 64)
 		if err != nil {
 			return nil, fmt.Errorf("invalid cursor value %s", v)
 		}
 		val := time.Unix(secs, 0)
 		return &db.Cursor{Value: val, Domain: domain}, nil
+	case db.SortByBacklinks:
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor value %s", v)
+		}
+		return &db.Cursor{Value: val, Domain: domain}, nil
+	case db.SortByRank:
+		val, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor value %s", v)
+		}
+		return &db.Cursor{Value: val, Domain: domain}, nil
 	default:
 		return nil, fmt.Errorf("unsupported sort by %s", sortBy)
 	}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+// SearchGrammar describes the syntax accepted by ParseSearch
+const SearchGrammar = `query   = term { " " term }
+term    = [ "-" ] ( key ":" value | boolkey | value )
+boolkey = name of a bool key, same as boolkey ":true"
+value   = word | '"' phrase '"'
+word    = any characters except whitespace and '"'
+phrase  = any characters except '"'`
+
+type SearchKey struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Negatable   bool   `json:"negatable"`
+	Description string `json:"description"`
+
+	apply func(f *db.ListFilters, value string, negated bool) error
+}
+
+// RedirectKinds maps names of redirect kinds accepted by the api to their values
+var RedirectKinds = map[string]db.RedirectKind{
+	"none":     db.RedirectNone,
+	"site":     db.RedirectSite,
+	"ton":      db.RedirectTon,
+	"clearnet": db.RedirectClearnet,
+}
+
+var SearchKeys = []SearchKey{
+	{
+		Name:        "zone",
+		Type:        "string",
+		Description: "show sites only from the domain zone, e.g. zone:.ton",
+		apply: func(f *db.ListFilters, value string, negated bool) error {
+			if !strings.HasPrefix(value, ".") {
+				value = "." + value
+			}
+			f.Zone = strings.ToLower(value)
+			return nil
+		},
+	},
+	{
+		Name:        "lang",
+		Type:        "string",
+		Description: "show sites only in the language (ISO 639-1 code), e.g. lang:en",
+		apply: func(f *db.ListFilters, value string, negated bool) error {
+			if len(value) != 2 {
+				return fmt.Errorf("must be ISO 639-1 code")
+			}
+			f.Lang = strings.ToLower(value)
+			return nil
+		},
+	},
+	{
+		Name:        "category",
+		Type:        "string",
+		Description: "show sites only from the category, e.g. category:nft",
+		apply: func(f *db.ListFilters, value string, negated bool) error {
+			f.Category = strings.ToLower(value)
+			return nil
+		},
+	},
+	{
+		Name:        "tech",
+		Type:        "string",
+		Description: "show sites only using the detected technology, e.g. tech:tonutils-reverse-proxy",
+		apply: func(f *db.ListFilters, value string, negated bool) error {
+			f.Tech = strings.ToLower(value)
+			return nil
+		},
+	},
+	{
+		Name:        "storage",
+		Type:        "bool",
+		Negatable:   true,
+		Description: "show only sites hosted in TON storage (true) or over ADNL (false)",
+		apply: func(f *db.ListFilters, value string, negated bool) error {
+			v, err := parseSearchBool(value, negated)
+			f.InStorage = &v
+			return err
+		},
+	},
+	{
+		Name:        "selfcontained",
+		Type:        "bool",
+		Negatable:   true,
+		Description: "show only sites that load nothing from the clearnet (true) or only ones that do (false)",
+		apply: func(f *db.ListFilters, value string, negated bool) error {
+			v, err := parseSearchBool(value, negated)
+			f.SelfContained = &v
+			return err
+		},
+	},
+	{
+		Name:        "redirect",
+		Type:        "string",
+		Description: "show only sites with the redirect of the main page: none, site, ton or clearnet, e.g. redirect:clearnet",
+		apply: func(f *db.ListFilters, value string, negated bool) error {
+			kind, ok := RedirectKinds[strings.ToLower(value)]
+			if !ok {
+				return fmt.Errorf("must be one of none, site, ton, clearnet")
+			}
+			f.Redirect = &kind
+			return nil
+		},
+	},
+	{
+		Name:        "punycode",
+		Type:        "bool",
+		Negatable:   true,
+		Description: "show only (true) or exclude (false) punycode domains",
+		apply: func(f *db.ListFilters, value string, negated bool) error {
+			v, err := parseSearchBool(value, negated)
+			f.Punycode = &v
+			return err
+		},
+	},
+	{
+		Name:        "spam",
+		Type:        "bool",
+		Negatable:   true,
+		Description: "include sites with a potentially spam content",
+		apply: func(f *db.ListFilters, value string, negated bool) (err error) {
+			f.Spam, err = parseSearchBool(value, negated)
+			return err
+		},
+	},
+	{
+		Name:        "inaccessible",
+		Type:        "bool",
+		Negatable:   true,
+		Description: "include inaccessible sites",
+		apply: func(f *db.ListFilters, value string, negated bool) (err error) {
+			f.Inaccessible, err = parseSearchBool(value, negated)
+			return err
+		},
+	},
+}
+
+var searchKeysByName = func() map[string]*SearchKey {
+	res := make(map[string]*SearchKey, len(SearchKeys))
+	for i := range SearchKeys {
+		res[SearchKeys[i].Name] = &SearchKeys[i]
+	}
+	return res
+}()
+
+type searchTerm struct {
+	key     string
+	value   string
+	quoted  bool
+	negated bool
+}
+
+// ParseSearch parses a structured search expression into filters. words and quoted phrases
+// without a key must be contained in the domain, its unicode form or the title, negated ones must not.
+// an expression without any known key is a plain text search, so "foo:bar" in it is a word as well.
+// a bare bool key is a shorthand for key:true, so "-spam" is "spam:false", quote it to search for the word
+func ParseSearch(expr string, f *db.ListFilters) error {
+	terms, err := splitSearch(expr)
+	if err != nil {
+		return err
+	}
+	for i, t := range terms {
+		if t.key != "" || t.quoted {
+			continue
+		}
+		if sk, ok := searchKeysByName[strings.ToLower(t.value)]; ok && sk.Type == "bool" {
+			terms[i].key = sk.Name
+			terms[i].value = "true"
+		}
+	}
+	structured := false
+	for _, t := range terms {
+		if _, ok := searchKeysByName[t.key]; ok {
+			structured = true
+			break
+		}
+	}
+	for _, t := range terms {
+		if t.key != "" && !structured {
+			t.value = t.key + ":" + t.value
+			t.key = ""
+		}
+		if t.key == "" {
+			if t.value == "" {
+				continue
+			}
+			if t.negated {
+				f.ExcludeTerms = append(f.ExcludeTerms, t.value)
+			} else {
+				f.Terms = append(f.Terms, t.value)
+			}
+			continue
+		}
+		sk, ok := searchKeysByName[t.key]
+		if !ok {
+			return fmt.Errorf("unknown search key %q, allowed keys are %s", t.key, searchKeyNames())
+		}
+		if t.negated && !sk.Negatable {
+			return fmt.Errorf("search key %q cannot be negated", t.key)
+		}
+		if t.value == "" && !t.quoted {
+			return fmt.Errorf("missing value for search key %q", t.key)
+		}
+		if err := sk.apply(f, t.value, t.negated); err != nil {
+			return fmt.Errorf("invalid value %q for search key %q: %w", t.value, t.key, err)
+		}
+	}
+	return nil
+}
+
+func splitSearch(expr string) ([]searchTerm, error) {
+	var terms []searchTerm
+	rest := expr
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return terms, nil
+		}
+		var t searchTerm
+		if rest[0] == '-' {
+			t.negated = true
+			rest = rest[1:]
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return unicode.IsSpace(r) || r == ':' || r == '"'
+		})
+		if end > 0 && rest[end] == ':' {
+			t.key = strings.ToLower(rest[:end])
+			rest = rest[end+1:]
+		}
+		var err error
+		t.value, t.quoted, rest, err = readSearchValue(rest)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+	}
+}
+
+func readSearchValue(s string) (value string, quoted bool, rest string, err error) {
+	if strings.HasPrefix(s, `"`) {
+		end := strings.IndexByte(s[1:], '"')
+		if end < 0 {
+			return "", true, "", fmt.Errorf("unterminated quote in search query")
+		}
+		return s[1 : end+1], true, s[end+2:], nil
+	}
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"'
+	})
+	if end < 0 {
+		return s, false, "", nil
+	}
+	return s[:end], false, s[end:], nil
+}
+
+func parseSearchBool(value string, negated bool) (bool, error) {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("expected true or false")
+	}
+	return v != negated, nil
+}
+
+func searchKeyNames() string {
+	names := make([]string, len(SearchKeys))
+	for i, key := range SearchKeys {
+		names[i] = key.Name
+	}
+	return strings.Join(names, ", ")
+}
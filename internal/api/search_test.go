@@ -0,0 +1,75 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+func TestParseSearch(t *testing.T) {
+	yes, no := true, false
+	clearnet := db.RedirectClearnet
+	tests := []struct {
+		expr string
+		want db.ListFilters
+	}{
+		{``, db.ListFilters{}},
+		{`wallet`, db.ListFilters{Terms: []string{"wallet"}}},
+		{`nft  market`, db.ListFilters{Terms: []string{"nft", "market"}}},
+		{`"nft market"`, db.ListFilters{Terms: []string{"nft market"}}},
+		{`-casino`, db.ListFilters{ExcludeTerms: []string{"casino"}}},
+		{`-"free ton"`, db.ListFilters{ExcludeTerms: []string{"free ton"}}},
+		{`zone:t.me`, db.ListFilters{Zone: ".t.me"}},
+		{`ZONE:.TON`, db.ListFilters{Zone: ".ton"}},
+		{`lang:EN category:nft tech:wordpress`, db.ListFilters{Lang: "en", Category: "nft", Tech: "wordpress"}},
+		{`redirect:clearnet`, db.ListFilters{Redirect: &clearnet}},
+		{`storage:true`, db.ListFilters{InStorage: &yes}},
+		{`-storage:true`, db.ListFilters{InStorage: &no}},
+		{`-storage:false`, db.ListFilters{InStorage: &yes}},
+		{`storage`, db.ListFilters{InStorage: &yes}},
+		{`-storage`, db.ListFilters{InStorage: &no}},
+		{`spam inaccessible`, db.ListFilters{Spam: true, Inaccessible: true}},
+		{`-spam`, db.ListFilters{}},
+		{`-punycode -selfcontained`, db.ListFilters{Punycode: &no, SelfContained: &no}},
+		{`"spam"`, db.ListFilters{Terms: []string{"spam"}}},
+		{`-"spam"`, db.ListFilters{ExcludeTerms: []string{"spam"}}},
+		{`foo:bar`, db.ListFilters{Terms: []string{"foo:bar"}}},
+		{`-foo:bar`, db.ListFilters{ExcludeTerms: []string{"foo:bar"}}},
+		{`zone:.ton "a:b"`, db.ListFilters{Zone: ".ton", Terms: []string{"a:b"}}},
+		{
+			`zone:.t.me storage:true lang:en "nft market" -spam`,
+			db.ListFilters{Zone: ".t.me", InStorage: &yes, Lang: "en", Terms: []string{"nft market"}},
+		},
+	}
+	for _, tt := range tests {
+		var got db.ListFilters
+		if err := ParseSearch(tt.expr, &got); err != nil {
+			t.Errorf("ParseSearch(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSearch(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchErrors(t *testing.T) {
+	tests := []string{
+		`"nft market`,
+		`zone:.ton "unterminated`,
+		`zone:`,
+		`zone:.ton foo:bar`,
+		`-zone:.ton`,
+		`storage:maybe`,
+		`lang:english`,
+		`lang:""`,
+		`redirect:somewhere`,
+	}
+	for _, expr := range tests {
+		var f db.ListFilters
+		if err := ParseSearch(expr, &f); err == nil {
+			t.Errorf("ParseSearch(%q) = %+v, want an error", expr, f)
+		}
+	}
+}
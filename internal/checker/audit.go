@@ -0,0 +1,97 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+const maxAuditProbes = 64
+const auditTimeout = 2 * timeout
+
+type auditTarget struct {
+	source string
+	kind   db.AuditKind
+}
+
+// audit looks for internal links and assets referenced by the crawled pages that do not exist.
+// bag references are checked against the file list, RLDP sites are probed with HEAD requests
+func audit(ctx context.Context, s *site, pages []crawledPage, robots *robots) []db.AuditIssue {
+	known := make(map[string]int, len(pages))
+	for _, page := range pages {
+		known[page.path] = page.status
+	}
+	targets := make(map[string]auditTarget)
+	order := make([]string, 0)
+	add := func(source string, refs []*url.URL, kind db.AuditKind) {
+		for _, ref := range refs {
+			target := requestPath(ref)
+			if s.inStorage {
+				target = ref.Path
+			}
+			if target == "" || target == source {
+				continue
+			}
+			if _, ok := targets[target]; ok {
+				continue
+			}
+			targets[target] = auditTarget{source: source, kind: kind}
+			order = append(order, target)
+		}
+	}
+	for _, page := range pages {
+		if page.status != http.StatusOK {
+			continue
+		}
+		add(page.path, internalRefs(s.domain, page.path, page.doc.links), db.AuditLink)
+		refs := make([]string, len(page.doc.resources))
+		for i, res := range page.doc.resources {
+			refs[i] = res.ref
+		}
+		add(page.path, internalRefs(s.domain, page.path, refs), db.AuditAsset)
+	}
+
+	issues := make([]db.AuditIssue, 0)
+	probes := 0
+	for _, target := range order {
+		if ctx.Err() != nil {
+			break
+		}
+		status, ok := known[target]
+		if !ok {
+			if !robots.allowed(target) || (!s.inStorage && probes >= maxAuditProbes) {
+				continue
+			}
+			probes++
+			resp, err := s.head(ctx, target)
+			if err != nil {
+				// unreachable targets are not reported, the site may just be slow
+				continue
+			}
+			status = resp.status
+		}
+		if !isBroken(status) {
+			continue
+		}
+		t := targets[target]
+		issues = append(issues, db.AuditIssue{
+			Source: t.source,
+			Target: target,
+			Kind:   t.kind,
+			Status: status,
+		})
+	}
+	slices.SortFunc(issues, func(a, b db.AuditIssue) int {
+		return strings.Compare(a.Target, b.Target)
+	})
+	return issues
+}
+
+// isBroken reports statuses that mean the resource is missing rather than protected or rate limited
+func isBroken(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusGone || status >= http.StatusInternalServerError
+}
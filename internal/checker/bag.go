@@ -0,0 +1,56 @@
+package checker
+
+import (
+	"context"
+	"math"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+const maxHealthPieces = 8
+const healthTimeout = timeout
+
+// weights of the bag health score, a bag is considered well seeded with healthyPeers peers
+const (
+	availabilityWeight = 0.7
+	peersWeight        = 0.3
+	healthyPeers       = 3
+)
+
+// bagHealth probes pieces spread across the bag to estimate how well it's seeded
+func (c *Checker) bagHealth(ctx context.Context, s *site) *db.BagHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	stats := s.bag.Stats()
+	pieces := samplePieces(stats.Pieces, maxHealthPieces)
+	var available float64
+	if len(pieces) > 0 {
+		available = float64(s.bag.ProbePieces(ctx, pieces)) / float64(len(pieces))
+	}
+	// peers are counted after probing since the pieces are requested from all of them
+	peers := s.bag.Stats().Peers
+	return &db.BagHealth{
+		Peers:     peers,
+		Size:      int64(stats.Size),
+		Files:     int(stats.Files),
+		Available: available,
+		Score:     healthScore(peers, available),
+	}
+}
+
+// healthScore rates the bag from 0 to 100 by the fraction of available pieces and the number of peers
+func healthScore(peers int, available float64) int {
+	score := availabilityWeight*available + peersWeight*float64(min(peers, healthyPeers))/healthyPeers
+	return int(math.Round(score * 100))
+}
+
+// samplePieces returns up to k evenly spaced piece ids out of n
+func samplePieces(n uint32, k int) []uint32 {
+	count := min(int(n), k)
+	pieces := make([]uint32, count)
+	for i := range count {
+		pieces[i] = uint32(uint64(i) * uint64(n) / uint64(count))
+	}
+	return pieces
+}
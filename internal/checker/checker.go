@@ -1,40 +1,96 @@
 package checker
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/oxylume/index/internal/db"
+	"github.com/oxylume/index/pkg/geoip"
 	"github.com/oxylume/index/pkg/proxy"
+	"github.com/oxylume/index/pkg/ttlcache"
 	"github.com/xssnick/tonutils-go/ton/dns"
 )
 
-const sniffSize = 512
+const maxPageSize = 256 << 10
 const timeout = 16 * time.Second
-const hold = timeout + timeout/4
+const spiderTimeout = 4 * timeout
+
+type Config struct {
+	CheckInterval time.Duration
+	Zones         []string
+	Spider        SpiderConfig
+	Taxonomy      *Taxonomy
+	Snapshots     db.SnapshotRetention
+	// UpstreamLimit limits concurrent checks of sites sharing an ADNL server or a bag, 0 for no limit
+	UpstreamLimit int
+	// ZoneWeights are relative shares of checks per zone, zones without a weight get 1
+	ZoneWeights map[string]float64
+	// Probes configures registered probes by their names, probes without config are enabled
+	Probes map[string]ProbeConfig
+	// GeoIP is optional
+	GeoIP *geoip.DB
+}
 
 type Checker struct {
-	dns           *dns.Client
-	bags          *proxy.BagProvider
-	rldp          *proxy.RLDPConnector
-	sites         *db.SitesStore
-	checkInterval time.Duration
-	closer        context.CancelFunc
+	dns       *dns.Client
+	bags      *proxy.BagProvider
+	rldp      *proxy.RLDPConnector
+	sites     *db.SitesStore
+	links     *db.LinksStore
+	pages     *db.PagesStore
+	favicons  *db.FaviconsStore
+	clearnet  *db.ClearnetStore
+	audits    *db.AuditStore
+	snapshots *db.SnapshotsStore
+	hosting   *db.HostingStore
+	probes    *db.ProbesStore
+	cfg       Config
+	robots    *ttlcache.Cache[robotsKey, *robots]
+	upstreams *upstreamLimiter
+	dnsErrors errorRate
+	dhtErrors errorRate
+	closer    context.CancelFunc
+}
+
+type result struct {
+	db.CheckResult
+	links    []string
+	domains  map[string]string
+	pages    []db.Page
+	sitemap  []db.SitemapEntry
+	favicon  *db.Favicon
+	deps     []db.ClearnetDep
+	issues   []db.AuditIssue
+	snapshot []byte
+	hosts    []db.Host
+	probes   []db.ProbeResult
+	// audited means the audit probe ran, the stored issues are kept otherwise
+	audited bool
+	// retry means the site could not be checked now and the check is postponed
+	retry bool
 }
 
-func NewChecker(dns *dns.Client, bags *proxy.BagProvider, rldp *proxy.RLDPConnector, sites *db.SitesStore, checkInterval time.Duration) *Checker {
+func NewChecker(dns *dns.Client, bags *proxy.BagProvider, rldp *proxy.RLDPConnector, sites *db.SitesStore, links *db.LinksStore, pages *db.PagesStore, favicons *db.FaviconsStore, clearnet *db.ClearnetStore, audits *db.AuditStore, snapshots *db.SnapshotsStore, hosting *db.HostingStore, probes *db.ProbesStore, cfg Config) *Checker {
 	return &Checker{
-		dns:           dns,
-		bags:          bags,
-		rldp:          rldp,
-		sites:         sites,
-		checkInterval: checkInterval,
+		dns:       dns,
+		bags:      bags,
+		rldp:      rldp,
+		sites:     sites,
+		links:     links,
+		pages:     pages,
+		favicons:  favicons,
+		clearnet:  clearnet,
+		audits:    audits,
+		snapshots: snapshots,
+		hosting:   hosting,
+		probes:    probes,
+		cfg:       cfg,
+		robots:    ttlcache.New[robotsKey, *robots](robotsTTL, maxRobotsCache),
+		upstreams: newUpstreamLimiter(cfg.UpstreamLimit),
 	}
 }
 
@@ -58,23 +114,89 @@ func (c *Checker) worker(ctx context.Context, domainsC <-chan string) {
 		if ctx.Err() != nil {
 			return
 		}
-		status, inStorage, spamContent := c.check(ctx, domain)
-		if err := c.sites.FinalizeCheck(ctx, domain, status, inStorage, spamContent); err != nil {
+		res := c.check(ctx, domain)
+		if res.retry {
+			if err := c.sites.Postpone(ctx, domain, retryDelay); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("[CHECKER] unable to postpone site check: %v", err)
+			}
+			continue
+		}
+		if err := c.sites.FinalizeCheck(ctx, domain, &res.CheckResult); err != nil {
 			if !errors.Is(err, context.Canceled) {
 				log.Printf("[CHECKER] unable to update site status: %v", err)
 			}
 			continue
 		}
+		// keep the last known links of temporarily inaccessible sites
+		if res.Status == db.StatusInaccessible {
+			continue
+		}
+		// the steps below are independent, one failing doesn't prevent the others
+		logStoreError("update site links", c.links.SetLinks(ctx, domain, res.links))
+		c.discover(ctx, res.domains)
+		logStoreError("update site clearnet dependencies", c.clearnet.SetDeps(ctx, domain, res.deps))
+		if res.audited {
+			logStoreError("update site audit", c.audits.SetIssues(ctx, domain, res.issues))
+		}
+		logStoreError("update site probe results", c.probes.SetResults(ctx, domain, res.probes))
+		logStoreError("update site hosts", c.hosting.SetHosts(ctx, domain, res.hosts))
+		if res.snapshot != nil {
+			_, err := c.snapshots.Save(ctx, domain, res.snapshot, c.cfg.Snapshots)
+			logStoreError("save site snapshot", err)
+		}
+		if res.favicon != nil {
+			logStoreError("update site favicon", c.favicons.Set(ctx, domain, res.favicon))
+		}
+		logStoreError("update site sitemap", c.pages.SetSitemap(ctx, domain, res.sitemap))
+		if c.cfg.Spider.Enabled {
+			logStoreError("update site pages", c.pages.SetPages(ctx, domain, res.pages))
+		}
+	}
+}
+
+func logStoreError(action string, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("[CHECKER] unable to %s: %v", action, err)
 	}
 }
 
+// hold returns for how long a reserved site stays locked for other checkers
+func (c *Checker) hold() time.Duration {
+	// main page, robots.txt, sitemaps and favicon are fetched with their own timeouts
+	hold := 4*timeout + healthTimeout + c.probesTimeout()
+	if c.cfg.Spider.Enabled {
+		hold += spiderTimeout
+	}
+	return hold + hold/4
+}
+
 func (c *Checker) reserver(ctx context.Context, domainsC chan<- string, reserveBatch int) {
 	defer close(domainsC)
+	weights := make([]float64, len(c.cfg.Zones))
+	for i, zone := range c.cfg.Zones {
+		weights[i] = 1
+		if w, ok := c.cfg.ZoneWeights[zone]; ok {
+			weights[i] = w
+		}
+	}
+	backoff := minBackoff
 	for {
 		if ctx.Err() != nil {
 			return
 		}
-		sites, err := c.sites.ReserveCheck(ctx, c.checkInterval, hold, reserveBatch)
+		// results are not trustworthy while the network is failing, it's better to wait until it recovers
+		if c.dnsErrors.high() || c.dhtErrors.high() {
+			log.Printf("[CHECKER] too many DHT or liteserver errors, pausing checks for %s", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		backoff = minBackoff
+		sites, err := c.sites.ReserveCheck(ctx, c.cfg.CheckInterval, c.hold(), reserveBatch, c.cfg.Zones, weights)
 		if err != nil {
 			if !errors.Is(err, context.Canceled) {
 				log.Printf("[CHECKER]: failed to get expired sites: %v", err)
@@ -101,71 +223,137 @@ func (c *Checker) reserver(ctx context.Context, domainsC chan<- string, reserveB
 
 // todo: it would be better to implement a block scanner to detect "TON Site" record changes
 // rather than asking each domain separately, especially long-term
-func (c *Checker) check(ctx context.Context, domain string) (db.SiteStatus, bool, bool) {
+func (c *Checker) check(ctx context.Context, domain string) *result {
 	resolved, err := c.dns.Resolve(ctx, domain)
 	if err != nil {
-		return db.StatusNoSite, false, false
+		failed := !errors.Is(err, dns.ErrNoSuchRecord)
+		c.dnsErrors.add(failed)
+		// liteservers are failing, the domain may still have a site
+		if failed && c.dnsErrors.high() {
+			return &result{retry: true}
+		}
+		return &result{CheckResult: db.CheckResult{Status: db.StatusNoSite}}
+	}
+	c.dnsErrors.add(false)
+	var wallet string
+	if addr := resolved.GetWalletRecord(); addr != nil {
+		wallet = addr.StringRaw()
 	}
 	id, inStorage := resolved.GetSiteRecord()
 	if id == nil {
-		return db.StatusNoSite, false, false
+		return &result{CheckResult: db.CheckResult{Status: db.StatusNoSite, Wallet: wallet}}
 	}
-	data, err := c.getSiteData(ctx, domain, id, inStorage)
-	if err != nil {
-		return db.StatusInaccessible, inStorage, false
+	if !c.upstreams.tryAcquire(id) {
+		return &result{retry: true}
 	}
-	return db.StatusAccessible, inStorage, containsSpamContent(data)
-}
-
-func (c *Checker) getSiteData(ctx context.Context, domain string, id []byte, inStorage bool) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-	var data []byte
-	if inStorage {
-		bag, err := c.bags.GetBag(ctx, id)
-		if err != nil {
-			return nil, err
+	defer c.upstreams.release(id)
+	s, resp, redir, latency, err := c.getSiteData(ctx, domain, id, inStorage)
+	if err != nil {
+		if isDHTFailure(err) && c.dhtErrors.high() {
+			return &result{retry: true}
 		}
-		info, err := bag.GetFileOffsets("index.html")
-		if err != nil {
-			return nil, err
+		res := &result{CheckResult: db.CheckResult{Status: db.StatusInaccessible, InStorage: inStorage, SiteId: id, Wallet: wallet}}
+		if inStorage {
+			// the bag could not be fetched at all, most likely there's no one seeding it.
+			// its size and files are unknown, so the stored ones are kept
+			res.Bag = &db.BagHealth{}
 		}
-		if info.Size == 0 {
-			return nil, fmt.Errorf("empty file")
+		return res
+	}
+	data := resp.body
+	doc := parseDocument(data)
+	// a redirect response leading off the site is not worth archiving
+	var snapshot []byte
+	if redir.kind == db.RedirectNone || redir.kind == db.RedirectSite {
+		snapshot = data
+		if r, ok := c.htmlRedirect(domain, resp.path, doc); ok {
+			redir = r
 		}
-		size := min(info.Size, sniffSize)
-
-		buf := bytes.NewBuffer(make([]byte, 0, size))
-		bag.WriteFileTo(ctx, buf, info, 0, size-1, 1)
-		data = buf.Bytes()
+	}
+	links, domains := c.resolveLinks(doc.links)
+	robots := c.getRobots(ctx, s)
+	var favicon *db.Favicon
+	if checkedAt, err := c.favicons.CheckedAt(ctx, domain); err == nil && time.Since(checkedAt) > faviconTTL {
+		favicon = c.getFavicon(ctx, s, doc)
+	}
+	res := &result{
+		CheckResult: db.CheckResult{
+			Status:      db.StatusAccessible,
+			InStorage:   inStorage,
+			Robots:      robots.policy(),
+			Latency:     latency,
+			ContentSize: len(doc.text),
+			Redirect:    redir.kind,
+			RedirectTo:  redir.target,
+			SiteId:      id,
+			Wallet:      wallet,
+		},
+		links:    links,
+		domains:  domains,
+		favicon:  favicon,
+		snapshot: snapshot,
+	}
+	if inStorage {
+		res.Bag = c.bagHealth(ctx, s)
 	} else {
-		conn, err := c.rldp.GetConnection(ctx, id)
-		if err != nil {
-			return nil, err
-		}
-		req := &proxy.Request{
-			Method:  "GET",
-			Url:     fmt.Sprintf("http://%s", domain),
-			Version: "HTTP/1.1",
-			Headers: []proxy.Header{
-				{Name: "Host", Value: domain},
-			},
-		}
-		resp, body, err := conn.SendRequest(ctx, req, nil)
-		if err != nil {
-			return nil, err
-		}
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("responded with non-ok status code %d", resp.StatusCode)
-		}
-		if resp.NoPayload {
-			return nil, fmt.Errorf("responded with empty payload")
+		res.hosts = c.hosts(s)
+	}
+	root := crawledPage{path: resp.path, status: resp.status, doc: doc}
+	crawled := []crawledPage{root}
+	// the main page is always checked for availability, robots.txt only limits deep indexing
+	if robots.allowed("/") {
+		res.sitemap = c.getSitemap(ctx, s, robots)
+		if c.cfg.Spider.Enabled {
+			crawled = c.spider(ctx, s, root, robots, res.sitemap)
+			res.pages = make([]db.Page, len(crawled))
+			for i, page := range crawled {
+				res.pages[i] = newPage(page)
+			}
 		}
-		data = make([]byte, sniffSize)
-		_, err = body.Read(data)
-		if err != nil && !errors.Is(err, io.EOF) {
-			return nil, err
+	}
+	res.deps = c.clearnetDeps(domain, crawled)
+	for _, page := range res.pages {
+		if page.Path != root.path {
+			res.ContentSize += len(page.Text)
 		}
 	}
-	return data, nil
+	res.probes = c.runProbes(ctx, &ProbeInput{
+		Domain:    domain,
+		Records:   resolved,
+		InStorage: inStorage,
+		Body:      data,
+		checker:   c,
+		site:      s,
+		main:      resp,
+		root:      root,
+		pages:     crawled,
+		robots:    robots,
+	}, res)
+	return res
+}
+
+// getSiteData fetches the main page of the site following redirects within the site and measures how long it took.
+// a site redirecting elsewhere is accessible, its main page is the redirect response
+func (c *Checker) getSiteData(ctx context.Context, domain string, id []byte, inStorage bool) (*site, *response, redirect, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	s, err := c.openSite(ctx, domain, id, inStorage)
+	if err != nil {
+		return nil, nil, redirect{}, 0, err
+	}
+	resp, redir, err := c.fetch(ctx, s, "/", maxPageSize)
+	if err != nil {
+		return nil, nil, redirect{}, 0, err
+	}
+	if redir.kind == db.RedirectTon || redir.kind == db.RedirectClearnet {
+		return s, resp, redir, time.Since(start), nil
+	}
+	if resp.status != http.StatusOK {
+		return nil, nil, redirect{}, 0, fmt.Errorf("responded with non-ok status code %d", resp.status)
+	}
+	if len(resp.body) == 0 {
+		return nil, nil, redirect{}, 0, fmt.Errorf("responded with empty payload")
+	}
+	return s, resp, redir, time.Since(start), nil
 }
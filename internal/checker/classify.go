@@ -0,0 +1,127 @@
+package checker
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+//go:embed taxonomy.json
+var defaultTaxonomy []byte
+
+// Taxonomy is a keyword model used to assign categories to sites. each keyword found in the page
+// text adds a point to its category, keywords found in the title are worth titleWeight points
+type Taxonomy struct {
+	MinScore      int                 `json:"minScore"`
+	MaxCategories int                 `json:"maxCategories"`
+	Categories    map[string][]string `json:"categories"`
+}
+
+const titleWeight = 3
+
+// LoadTaxonomy reads the taxonomy from the file or returns the one that ships with the project if path is empty
+func LoadTaxonomy(path string) (*Taxonomy, error) {
+	data := defaultTaxonomy
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read taxonomy: %w", err)
+		}
+	}
+	var t Taxonomy
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("unable to parse taxonomy: %w", err)
+	}
+	if t.MaxCategories <= 0 {
+		return nil, fmt.Errorf("taxonomy maxCategories must be positive, got %d", t.MaxCategories)
+	}
+	if t.MinScore < 0 {
+		return nil, fmt.Errorf("taxonomy minScore must not be negative, got %d", t.MinScore)
+	}
+	for category, keywords := range t.Categories {
+		if len(keywords) == 0 {
+			return nil, fmt.Errorf("taxonomy category %s has no keywords", category)
+		}
+		for i, keyword := range keywords {
+			keywords[i] = strings.ToLower(keyword)
+		}
+		t.Categories[category] = keywords
+	}
+	return &t, nil
+}
+
+// Classify returns up to MaxCategories categories ordered by their score
+func (t *Taxonomy) Classify(title string, text string) []string {
+	title = strings.ToLower(title)
+	text = strings.ToLower(text)
+	titleWords := countWords(title)
+	textWords := countWords(text)
+
+	type scored struct {
+		category string
+		score    int
+	}
+	scores := make([]scored, 0, len(t.Categories))
+	for category, keywords := range t.Categories {
+		score := 0
+		for _, keyword := range keywords {
+			if strings.Contains(keyword, " ") {
+				score += strings.Count(title, keyword)*titleWeight + strings.Count(text, keyword)
+				continue
+			}
+			score += titleWords[keyword]*titleWeight + textWords[keyword]
+		}
+		if score >= t.MinScore {
+			scores = append(scores, scored{category: category, score: score})
+		}
+	}
+	slices.SortFunc(scores, func(a, b scored) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return strings.Compare(a.category, b.category)
+	})
+	categories := make([]string, 0, t.MaxCategories)
+	for _, s := range scores[:min(len(scores), t.MaxCategories)] {
+		categories = append(categories, s.category)
+	}
+	return categories
+}
+
+func countWords(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		counts[word]++
+	}
+	return counts
+}
+
+// classify detects language and categories of the site using the main page and pages found by the spider
+func (c *Checker) classify(root crawledPage, pages []db.Page) (string, []string) {
+	var text strings.Builder
+	text.WriteString(root.doc.text)
+	for _, page := range pages {
+		if page.Path == root.path || text.Len() >= maxTextSize*4 {
+			continue
+		}
+		text.WriteByte(' ')
+		text.WriteString(page.Title)
+		text.WriteByte(' ')
+		text.WriteString(page.Text)
+	}
+	lang := detectLang(root.doc.title+" "+text.String(), root.doc.lang)
+	if c.cfg.Taxonomy == nil {
+		return lang, nil
+	}
+	return lang, c.cfg.Taxonomy.Classify(root.doc.title, text.String())
+}
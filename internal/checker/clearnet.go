@@ -0,0 +1,91 @@
+package checker
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+const maxClearnetDeps = 256
+const maxDepUrlSize = 1024
+
+// trackerHosts are analytics and advertising services, resources loaded from them are reported as beacons
+var trackerHosts = []string{
+	"google-analytics.com",
+	"googletagmanager.com",
+	"analytics.google.com",
+	"doubleclick.net",
+	"googlesyndication.com",
+	"mc.yandex.ru",
+	"mc.yandex.com",
+	"connect.facebook.net",
+	"static.cloudflareinsights.com",
+	"plausible.io",
+	"cdn.segment.com",
+	"cdn.mxpnl.com",
+	"static.hotjar.com",
+	"clarity.ms",
+	"cdn.amplitude.com",
+	"counter.yadro.ru",
+	"top-fwz1.mail.ru",
+	"matomo.cloud",
+	"umami.is",
+}
+
+// clearnetDeps returns unique resources of the crawled pages that are loaded from outside of the TON network
+func (c *Checker) clearnetDeps(domain string, pages []crawledPage) []db.ClearnetDep {
+	seen := make(map[string]struct{})
+	deps := make([]db.ClearnetDep, 0)
+	for _, page := range pages {
+		base := &url.URL{Scheme: "http", Host: domain, Path: page.path}
+		for _, res := range page.doc.resources {
+			if len(deps) >= maxClearnetDeps {
+				break
+			}
+			ref, err := url.Parse(strings.TrimSpace(res.ref))
+			if err != nil {
+				continue
+			}
+			u := base.ResolveReference(ref)
+			if u.Scheme != "http" && u.Scheme != "https" {
+				continue
+			}
+			host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+			if host == "" || host == domain {
+				continue
+			}
+			if _, _, ok := c.linkTarget(u.String()); ok {
+				continue
+			}
+			u.Fragment = ""
+			link := u.String()
+			if len(link) > maxDepUrlSize {
+				continue
+			}
+			if _, ok := seen[link]; ok {
+				continue
+			}
+			seen[link] = struct{}{}
+			kind := res.kind
+			if isTracker(host) {
+				kind = "analytics"
+			}
+			deps = append(deps, db.ClearnetDep{Url: link, Host: host, Kind: kind})
+		}
+	}
+	slices.SortFunc(deps, func(a, b db.ClearnetDep) int {
+		return strings.Compare(a.Url, b.Url)
+	})
+	return deps
+}
+
+func isTracker(host string) bool {
+	for _, tracker := range trackerHosts {
+		if host == tracker || strings.HasSuffix(host, "."+tracker) {
+			return true
+		}
+	}
+	return false
+}
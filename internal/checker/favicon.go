@@ -0,0 +1,78 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+const maxFaviconSize = 64 << 10
+const faviconTTL = 24 * time.Hour
+
+var faviconTypes = map[string]struct{}{
+	"image/x-icon": {},
+	"image/png":    {},
+	"image/gif":    {},
+	"image/jpeg":   {},
+	"image/webp":   {},
+	"image/bmp":    {},
+}
+
+// getFavicon tries icons declared by the main page in order and falls back to /favicon.ico.
+// the returned favicon has no content if none of the candidates is a valid image
+func (c *Checker) getFavicon(ctx context.Context, s *site, root *document) *db.Favicon {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	base := &url.URL{Scheme: "http", Host: s.domain, Path: "/"}
+	candidates := make([]string, 0, len(root.icons)+1)
+	for _, icon := range root.icons {
+		ref, err := url.Parse(strings.TrimSpace(icon))
+		if err != nil {
+			continue
+		}
+		if path, ok := sitePath(s.domain, base.ResolveReference(ref).String()); ok {
+			candidates = append(candidates, path)
+		}
+	}
+	candidates = append(candidates, "/favicon.ico")
+
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		resp, err := s.get(ctx, candidate, maxFaviconSize+1)
+		if err != nil || resp.status != http.StatusOK || len(resp.body) > maxFaviconSize {
+			continue
+		}
+		if contentType, ok := faviconType(candidate, resp.body); ok {
+			return &db.Favicon{
+				Content:     resp.body,
+				ContentType: contentType,
+			}
+		}
+	}
+	return &db.Favicon{}
+}
+
+// faviconType sniffs the image type by its content so sites cannot make us serve arbitrary data
+func faviconType(name string, data []byte) (string, bool) {
+	if len(data) == 0 {
+		return "", false
+	}
+	contentType := http.DetectContentType(data)
+	if _, ok := faviconTypes[contentType]; ok {
+		return contentType, true
+	}
+	ext := strings.ToLower(path.Ext(strings.SplitN(name, "?", 2)[0]))
+	if ext == ".svg" && bytes.Contains(bytes.ToLower(data[:min(len(data), 1024)]), []byte("<svg")) {
+		return "image/svg+xml", true
+	}
+	return "", false
+}
@@ -0,0 +1,100 @@
+package checker
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/oxylume/index/pkg/proxy"
+)
+
+type techSource int
+
+const (
+	sourceHeader techSource = iota
+	sourceGenerator
+	sourceBody
+)
+
+type techRule struct {
+	tech    string
+	source  techSource
+	header  string
+	pattern *regexp.Regexp
+}
+
+// rules are matched against lowercased values. proxy software usually reveals itself through
+// the headers it adds, web stacks through headers, generator meta tags and markers in the markup
+var techRules = []techRule{
+	// TON proxies
+	{tech: "tonutils-reverse-proxy", source: sourceHeader, header: "Server", pattern: regexp.MustCompile(`tonutils`)},
+	{tech: "tonutils-reverse-proxy", source: sourceHeader, header: "X-Powered-By", pattern: regexp.MustCompile(`tonutils`)},
+	{tech: "rldp-http-proxy", source: sourceHeader, header: "Server", pattern: regexp.MustCompile(`rldp-http-proxy`)},
+	{tech: "rldp-http-proxy", source: sourceHeader, header: "X-Powered-By", pattern: regexp.MustCompile(`rldp-http-proxy`)},
+	// web servers
+	{tech: "nginx", source: sourceHeader, header: "Server", pattern: regexp.MustCompile(`nginx|openresty`)},
+	{tech: "apache", source: sourceHeader, header: "Server", pattern: regexp.MustCompile(`apache`)},
+	{tech: "caddy", source: sourceHeader, header: "Server", pattern: regexp.MustCompile(`caddy`)},
+	{tech: "lighttpd", source: sourceHeader, header: "Server", pattern: regexp.MustCompile(`lighttpd`)},
+	{tech: "iis", source: sourceHeader, header: "Server", pattern: regexp.MustCompile(`microsoft-iis`)},
+	{tech: "python", source: sourceHeader, header: "Server", pattern: regexp.MustCompile(`python|gunicorn|uvicorn|werkzeug`)},
+	// backends
+	{tech: "express", source: sourceHeader, header: "X-Powered-By", pattern: regexp.MustCompile(`express`)},
+	{tech: "php", source: sourceHeader, header: "X-Powered-By", pattern: regexp.MustCompile(`php`)},
+	{tech: "asp.net", source: sourceHeader, header: "X-Powered-By", pattern: regexp.MustCompile(`asp\.net`)},
+	{tech: "next.js", source: sourceHeader, header: "X-Powered-By", pattern: regexp.MustCompile(`next\.js`)},
+	// generators
+	{tech: "wordpress", source: sourceGenerator, pattern: regexp.MustCompile(`wordpress`)},
+	{tech: "hugo", source: sourceGenerator, pattern: regexp.MustCompile(`hugo`)},
+	{tech: "jekyll", source: sourceGenerator, pattern: regexp.MustCompile(`jekyll`)},
+	{tech: "gatsby", source: sourceGenerator, pattern: regexp.MustCompile(`gatsby`)},
+	{tech: "docusaurus", source: sourceGenerator, pattern: regexp.MustCompile(`docusaurus`)},
+	{tech: "hexo", source: sourceGenerator, pattern: regexp.MustCompile(`hexo`)},
+	{tech: "astro", source: sourceGenerator, pattern: regexp.MustCompile(`astro`)},
+	{tech: "vitepress", source: sourceGenerator, pattern: regexp.MustCompile(`vitepress`)},
+	{tech: "tilda", source: sourceGenerator, pattern: regexp.MustCompile(`tilda`)},
+	// frameworks
+	{tech: "next.js", source: sourceBody, pattern: regexp.MustCompile(`__next_data__|/_next/static/`)},
+	{tech: "nuxt", source: sourceBody, pattern: regexp.MustCompile(`__nuxt__|/_nuxt/`)},
+	{tech: "react", source: sourceBody, pattern: regexp.MustCompile(`data-reactroot|react-dom|id="root"></div>`)},
+	{tech: "vue", source: sourceBody, pattern: regexp.MustCompile(`data-v-[0-9a-f]{8}|vue(\.runtime)?(\.global)?(\.prod)?\.js`)},
+	{tech: "angular", source: sourceBody, pattern: regexp.MustCompile(`ng-version=`)},
+	{tech: "svelte", source: sourceBody, pattern: regexp.MustCompile(`/_app/immutable/|svelte-[0-9a-z]{5,}`)},
+	{tech: "astro", source: sourceBody, pattern: regexp.MustCompile(`/_astro/|astro-island`)},
+	{tech: "vite", source: sourceBody, pattern: regexp.MustCompile(`/assets/index-[0-9a-z_-]{8}\.js`)},
+	{tech: "wordpress", source: sourceBody, pattern: regexp.MustCompile(`/wp-content/|/wp-includes/`)},
+	{tech: "tonconnect", source: sourceBody, pattern: regexp.MustCompile(`tonconnect`)},
+}
+
+// fingerprint returns sorted unique technologies detected on the main page
+func fingerprint(resp *response, doc *document) []string {
+	body := bytes.ToLower(resp.body)
+	generator := strings.ToLower(doc.generator)
+	found := make([]string, 0)
+	for _, rule := range techRules {
+		var matched bool
+		switch rule.source {
+		case sourceHeader:
+			matched = rule.pattern.MatchString(strings.ToLower(headerValue(resp.headers, rule.header)))
+		case sourceGenerator:
+			matched = generator != "" && rule.pattern.MatchString(generator)
+		case sourceBody:
+			matched = rule.pattern.Match(body)
+		}
+		if matched {
+			found = append(found, rule.tech)
+		}
+	}
+	slices.Sort(found)
+	return slices.Compact(found)
+}
+
+func headerValue(headers []proxy.Header, name string) string {
+	for _, header := range headers {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+	return ""
+}
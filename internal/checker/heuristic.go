@@ -5,19 +5,26 @@ import (
 	"regexp"
 )
 
+// redirects are bad, unless they lead within the site itself
+var refreshRule = regexp.MustCompile(`<meta\s+http-equiv\s*=\s*["']refresh["']\s+`)
+
 var rules = []*regexp.Regexp{
-	// redirects are bad
-	regexp.MustCompile(`<meta\s+http-equiv\s*=\s*["']refresh["']\s+`),
+	refreshRule,
 	// captcha is same as redirect but with extra steps
 	regexp.MustCompile(`<title>\s*вы не робот\?\s*<\/title>`),
 }
 
-func containsSpamContent(data []byte) bool {
+// matchSpamRules returns patterns of the rules the page matches. refresh of a page within the site, e.g. to /en/, is not spam
+func matchSpamRules(data []byte, siteRefresh bool) []string {
 	data = bytes.ToLower(data)
+	matched := make([]string, 0)
 	for _, rule := range rules {
+		if rule == refreshRule && siteRefresh {
+			continue
+		}
 		if rule.Match(data) {
-			return true
+			matched = append(matched, rule.String())
 		}
 	}
-	return false
+	return matched
 }
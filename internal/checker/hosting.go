@@ -0,0 +1,26 @@
+package checker
+
+import (
+	"github.com/oxylume/index/internal/db"
+)
+
+// hosts returns udp addresses advertised by the ADNL site enriched with GeoIP data if available
+func (c *Checker) hosts(s *site) []db.Host {
+	addresses := s.conn.Addresses()
+	hosts := make([]db.Host, len(addresses))
+	for i, addr := range addresses {
+		hosts[i] = db.Host{
+			IP:   addr.Addr(),
+			Port: int(addr.Port()),
+		}
+		if c.cfg.GeoIP == nil {
+			continue
+		}
+		if info := c.cfg.GeoIP.Lookup(addr.Addr()); info != nil {
+			hosts[i].Country = info.Country
+			hosts[i].Asn = info.Asn
+			hosts[i].Org = info.Org
+		}
+	}
+	return hosts
+}
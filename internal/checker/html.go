@@ -0,0 +1,257 @@
+package checker
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const maxTextSize = 64 << 10
+const maxTitleSize = 256
+const maxIcons = 8
+const maxResources = 256
+
+// maxRedirectText is the most text a page may have for its javascript location redirect to be taken as
+// a redirect of the page rather than, say, a button leading somewhere
+const maxRedirectText = 256
+
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"area":   "href",
+	"iframe": "src",
+	"form":   "action",
+}
+
+// resourceAttrs are attributes of tags that make browsers load a subresource
+var resourceAttrs = map[string][]string{
+	"script": {"src"},
+	"img":    {"src", "srcset"},
+	"source": {"src", "srcset"},
+	"video":  {"src", "poster"},
+	"audio":  {"src"},
+	"track":  {"src"},
+	"iframe": {"src"},
+	"embed":  {"src"},
+	"object": {"data"},
+}
+
+var resourceKinds = map[string]string{
+	"script": "script",
+	"img":    "image",
+	"source": "media",
+	"video":  "media",
+	"audio":  "media",
+	"track":  "media",
+	"iframe": "iframe",
+	"embed":  "iframe",
+	"object": "iframe",
+}
+
+var skipTextTags = map[string]struct{}{
+	"script":   {},
+	"style":    {},
+	"template": {},
+	"svg":      {},
+}
+
+type document struct {
+	title     string
+	text      string
+	lang      string
+	links     []string
+	icons     []string
+	generator string
+	resources []resource
+	// redirect is the target of a meta refresh or a javascript location redirect
+	redirect string
+	// refresh is the target of the first meta refresh
+	refresh string
+	// jsRedirect is the first top-level location redirect of inline scripts
+	jsRedirect string
+}
+
+// resource is a reference to a subresource loaded by the page, e.g. a script or an image
+type resource struct {
+	kind string
+	ref  string
+}
+
+// parseDocument extracts everything the checker needs from an html page in a single pass
+func parseDocument(data []byte) *document {
+	doc := &document{}
+	var title, text strings.Builder
+	var inTitle, inScript bool
+	var skip int
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			doc.title = truncate(collapseSpaces(title.String()), maxTitleSize)
+			doc.text = truncate(collapseSpaces(text.String()), maxTextSize)
+			doc.redirect = doc.refresh
+			if doc.redirect == "" && len(doc.text) <= maxRedirectText {
+				doc.redirect = doc.jsRedirect
+			}
+			return doc
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			tag := string(name)
+			if tt == html.StartTagToken {
+				if _, ok := skipTextTags[tag]; ok {
+					skip++
+				}
+				if tag == "title" {
+					inTitle = true
+				}
+				if tag == "script" {
+					inScript = true
+				}
+			}
+			if hasAttr {
+				doc.parseAttrs(tokenizer, tag)
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if _, ok := skipTextTags[tag]; ok && skip > 0 {
+				skip--
+			}
+			if tag == "title" {
+				inTitle = false
+			}
+			if tag == "script" {
+				inScript = false
+			}
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+				continue
+			}
+			if inScript {
+				doc.parseScript(tokenizer.Text())
+				continue
+			}
+			if skip > 0 || text.Len() >= maxTextSize {
+				continue
+			}
+			text.Write(tokenizer.Text())
+			text.WriteByte(' ')
+		}
+	}
+}
+
+func (d *document) parseAttrs(tokenizer *html.Tokenizer, tag string) {
+	attrs := make(map[string]string)
+	for {
+		key, val, more := tokenizer.TagAttr()
+		attrs[string(key)] = string(val)
+		if !more {
+			break
+		}
+	}
+	if attr, ok := linkAttrs[tag]; ok && attrs[attr] != "" && len(d.links) < maxLinks {
+		d.links = append(d.links, attrs[attr])
+	}
+	for _, attr := range resourceAttrs[tag] {
+		if attrs[attr] == "" {
+			continue
+		}
+		if attr == "srcset" {
+			for _, candidate := range strings.Split(attrs[attr], ",") {
+				if fields := strings.Fields(candidate); len(fields) > 0 {
+					d.addResource(resourceKinds[tag], fields[0])
+				}
+			}
+			continue
+		}
+		d.addResource(resourceKinds[tag], attrs[attr])
+	}
+	switch tag {
+	case "html":
+		d.lang = attrs["lang"]
+	case "meta":
+		if strings.EqualFold(attrs["name"], "generator") {
+			d.generator = attrs["content"]
+		}
+		if strings.EqualFold(attrs["http-equiv"], "refresh") && d.refresh == "" {
+			d.refresh = parseRefresh(attrs["content"])
+		}
+	case "link":
+		if isIconRel(attrs["rel"]) && attrs["href"] != "" && len(d.icons) < maxIcons {
+			d.icons = append(d.icons, attrs["href"])
+		}
+		if kind := linkResourceKind(attrs["rel"], attrs["as"]); kind != "" && attrs["href"] != "" {
+			d.addResource(kind, attrs["href"])
+		}
+	}
+}
+
+func (d *document) addResource(kind string, ref string) {
+	if len(d.resources) < maxResources {
+		d.resources = append(d.resources, resource{kind: kind, ref: ref})
+	}
+}
+
+// parseScript looks for well known trackers loaded by inline scripts, e.g. analytics snippets, and location redirects
+func (d *document) parseScript(text []byte) {
+	if d.jsRedirect == "" {
+		d.jsRedirect = parseJsRedirect(text)
+	}
+	lower := bytes.ToLower(text)
+	for _, host := range trackerHosts {
+		if bytes.Contains(lower, []byte(host)) {
+			d.addResource("analytics", "https://"+host+"/")
+		}
+	}
+}
+
+// linkResourceKind returns the kind of the subresource loaded by a link tag or empty string if it does not load any
+func linkResourceKind(rel string, as string) string {
+	for _, value := range strings.Fields(strings.ToLower(rel)) {
+		switch value {
+		case "stylesheet":
+			return "style"
+		case "icon", "apple-touch-icon":
+			return "image"
+		case "modulepreload":
+			return "script"
+		case "preload", "prefetch":
+			switch strings.ToLower(as) {
+			case "script", "worker":
+				return "script"
+			case "style":
+				return "style"
+			case "font":
+				return "font"
+			case "image":
+				return "image"
+			case "audio", "video", "track":
+				return "media"
+			}
+		}
+	}
+	return ""
+}
+
+func isIconRel(rel string) bool {
+	for _, value := range strings.Fields(strings.ToLower(rel)) {
+		if value == "icon" || value == "apple-touch-icon" {
+			return true
+		}
+	}
+	return false
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate cuts s to at most size bytes without breaking utf-8 sequences
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	return strings.ToValidUTF8(s[:size], "")
+}
@@ -0,0 +1,127 @@
+package checker
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const minLangWords = 5
+
+// scripts that are (almost) exclusively used by a single language
+var scriptLangs = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+	{unicode.Greek, "el"},
+}
+
+// the most frequent words of languages sharing latin and cyrillic scripts
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "you", "that", "it", "for", "with", "your", "are", "on", "this"},
+	"ru": {"и", "в", "не", "на", "что", "с", "как", "это", "по", "для", "вы", "мы", "от", "все", "или"},
+	"uk": {"і", "й", "та", "що", "на", "не", "це", "як", "до", "для", "ви", "ми", "від", "або", "з"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "sie", "ein", "zu", "den", "auf", "für", "ich", "wir"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "pour", "que", "vous", "dans", "pas", "sur", "avec", "nous"},
+	"es": {"el", "la", "de", "que", "y", "los", "las", "es", "por", "para", "con", "una", "su", "del", "como"},
+	"pt": {"o", "a", "de", "que", "e", "os", "não", "para", "com", "uma", "um", "do", "da", "em", "você"},
+	"it": {"il", "di", "che", "e", "la", "per", "non", "sono", "una", "con", "del", "della", "gli", "questo", "anche"},
+	"tr": {"ve", "bir", "bu", "için", "ile", "da", "de", "ne", "çok", "daha", "olarak", "gibi", "ben", "sen", "var"},
+	"id": {"dan", "yang", "di", "ini", "itu", "untuk", "dengan", "tidak", "ada", "dari", "kami", "anda", "akan", "juga", "saya"},
+}
+
+var stopwordLangs = func() map[string][]string {
+	res := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, word := range words {
+			res[word] = append(res[word], lang)
+		}
+	}
+	return res
+}()
+
+// detectLang guesses the dominant language of the text and returns its ISO 639-1 code.
+// unique scripts decide the language right away, otherwise the most frequent stopwords do.
+// declared is the language from the html lang attribute which is used when the text is not conclusive
+func detectLang(text string, declared string) string {
+	declared = normalizeLang(declared)
+	scripts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scriptLangs {
+			if unicode.Is(s.table, r) {
+				scripts[s.lang]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return declared
+	}
+	// japanese text is full of han characters so kana decides
+	if scripts["ja"]*10 > letters {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for _, lang := range slices.Sorted(maps.Keys(scripts)) {
+		count := scripts[lang]
+		if count > bestCount {
+			best, bestCount = lang, count
+		}
+	}
+	if bestCount*2 > letters {
+		return best
+	}
+
+	scores := make(map[string]int)
+	matched := 0
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		langs, ok := stopwordLangs[word]
+		if !ok {
+			continue
+		}
+		matched++
+		for _, lang := range langs {
+			scores[lang]++
+		}
+	}
+	if matched < minLangWords {
+		return declared
+	}
+	best, bestCount = "", 0
+	for _, lang := range slices.Sorted(maps.Keys(scores)) {
+		count := scores[lang]
+		// prefer the declared language among equally scored ones
+		if count > bestCount || (count == bestCount && lang == declared) {
+			best, bestCount = lang, count
+		}
+	}
+	return best
+}
+
+// normalizeLang reduces language tags like "en-US" to a bare language code
+func normalizeLang(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag, _, _ = strings.Cut(tag, "-")
+	tag, _, _ = strings.Cut(tag, "_")
+	if len(tag) != 2 {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,105 @@
+package checker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/oxylume/index/pkg/proxy"
+	"github.com/xssnick/tonutils-go/adnl/dht"
+)
+
+// retryDelay is for how long a postponed check is delayed
+const retryDelay = time.Minute
+
+const (
+	errorWindow     = time.Minute
+	errorSamples    = 64
+	minErrorSamples = 16
+	maxErrorRate    = 0.5
+	minBackoff      = 5 * time.Second
+	maxBackoff      = 2 * time.Minute
+)
+
+// upstreamLimiter caps the number of sites served by the same ADNL server or bag that are checked concurrently
+type upstreamLimiter struct {
+	mu    sync.Mutex
+	limit int
+	busy  map[string]int
+}
+
+func newUpstreamLimiter(limit int) *upstreamLimiter {
+	return &upstreamLimiter{
+		limit: limit,
+		busy:  make(map[string]int),
+	}
+}
+
+// tryAcquire does not wait, the limit is not applied if it's not positive
+func (l *upstreamLimiter) tryAcquire(id []byte) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.busy[string(id)] >= l.limit {
+		return false
+	}
+	l.busy[string(id)]++
+	return true
+}
+
+func (l *upstreamLimiter) release(id []byte) {
+	if l.limit <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.busy[string(id)] <= 1 {
+		delete(l.busy, string(id))
+		return
+	}
+	l.busy[string(id)]--
+}
+
+type outcome struct {
+	at     time.Time
+	failed bool
+}
+
+// errorRate tracks failures of the latest requests to an upstream network within errorWindow
+type errorRate struct {
+	mu       sync.Mutex
+	outcomes [errorSamples]outcome
+	next     int
+}
+
+func (r *errorRate) add(failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcomes[r.next] = outcome{at: time.Now(), failed: failed}
+	r.next = (r.next + 1) % errorSamples
+}
+
+// high reports whether most of the recent requests failed. without enough recent samples it's never high,
+// so checks resume once the window passes
+func (r *errorRate) high() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total, failed int
+	for _, o := range r.outcomes {
+		if o.at.IsZero() || time.Since(o.at) > errorWindow {
+			continue
+		}
+		total++
+		if o.failed {
+			failed++
+		}
+	}
+	return total >= minErrorSamples && float64(failed) > float64(total)*maxErrorRate
+}
+
+// isDHTFailure tells apart DHT being unavailable from an ADNL server that is just not published there
+func isDHTFailure(err error) bool {
+	return errors.Is(err, proxy.ErrDHTLookup) && !errors.Is(err, dht.ErrDHTValueIsNotFound)
+}
@@ -0,0 +1,112 @@
+package checker
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/oxylume/index/internal/db"
+	"golang.org/x/net/idna"
+)
+
+const maxLinks = 256
+const maxDiscover = 16
+
+// linkTarget resolves a link reference to a TON network host. domains inside known zones
+// are reduced to the second level (the one that is registered as nft), .adnl and .bag hosts are kept as is
+func (c *Checker) linkTarget(link string) (target string, zone string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", "", false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", "", false
+	}
+	host, err = idna.Punycode.ToASCII(host)
+	if err != nil {
+		return "", "", false
+	}
+	if strings.HasSuffix(host, ".adnl") || strings.HasSuffix(host, ".bag") {
+		return host, "", true
+	}
+	for _, zone := range c.cfg.Zones {
+		name, found := strings.CutSuffix(host, zone)
+		if !found || name == "" {
+			continue
+		}
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[i+1:]
+		}
+		return name + zone, zone, true
+	}
+	return "", "", false
+}
+
+// resolveLinks returns unique TON network targets of the links and the subset of them that are domains
+func (c *Checker) resolveLinks(links []string) (targets []string, domains map[string]string) {
+	seen := make(map[string]struct{}, len(links))
+	domains = make(map[string]string)
+	for _, link := range links {
+		target, zone, ok := c.linkTarget(link)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[target]; ok {
+			continue
+		}
+		seen[target] = struct{}{}
+		targets = append(targets, target)
+		if zone != "" {
+			domains[target] = zone
+		}
+	}
+	return targets, domains
+}
+
+// discover registers linked domains we have not seen yet so the crawler does not have to catch up first
+func (c *Checker) discover(ctx context.Context, domains map[string]string) {
+	if len(domains) == 0 {
+		return
+	}
+	names := make([]string, 0, len(domains))
+	for domain := range domains {
+		names = append(names, domain)
+	}
+	unknown, err := c.sites.FilterUnknown(ctx, names)
+	if err != nil {
+		log.Printf("[CHECKER] unable to filter unknown domains: %v", err)
+		return
+	}
+	sites := make([]db.SiteCreate, 0, min(len(unknown), maxDiscover))
+	for _, domain := range unknown[:min(len(unknown), maxDiscover)] {
+		resolved, err := c.dns.Resolve(ctx, domain)
+		if err != nil {
+			continue
+		}
+		addr := resolved.GetNFTAddress()
+		if addr == nil {
+			continue
+		}
+		unicode, err := idna.Punycode.ToUnicode(domain)
+		if err != nil {
+			unicode = domain
+		}
+		sites = append(sites, db.SiteCreate{
+			Domain:  domain,
+			Unicode: unicode,
+			Zone:    domains[domain],
+			Address: addr.StringRaw(),
+		})
+	}
+	if len(sites) == 0 {
+		return
+	}
+	if err := c.sites.AddDomains(ctx, sites...); err != nil {
+		log.Printf("[CHECKER] unable to register discovered domains: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/oxylume/index/internal/db"
+	"github.com/xssnick/tonutils-go/ton/dns"
+)
+
+// localProbeTimeout limits probes that only inspect already fetched pages
+const localProbeTimeout = 2 * time.Second
+
+// Probe is a check run against every accessible site after its pages are crawled.
+// findings of probes are stored as is, built-in probes also fill the site record
+type Probe interface {
+	// Name must be unique, it identifies the probe in the config and stored results
+	Name() string
+	// Timeout is the default time limit of a single run
+	Timeout() time.Duration
+	Run(ctx context.Context, in *ProbeInput, out *ProbeOutput) error
+}
+
+type ProbeConfig struct {
+	Disabled bool
+	// Timeout overrides the default timeout of the probe if non-zero
+	Timeout time.Duration
+}
+
+// ProbeInput is what the checker knows about the site
+type ProbeInput struct {
+	Domain    string
+	Records   *dns.Domain
+	InStorage bool
+	// Body is the main page of the site
+	Body []byte
+
+	checker *Checker
+	site    *site
+	main    *response
+	root    crawledPage
+	pages   []crawledPage
+	robots  *robots
+}
+
+// Get requests the path from the site reading up to limit bytes of the body
+func (in *ProbeInput) Get(ctx context.Context, path string, limit int64) (int, []byte, error) {
+	resp, err := in.site.get(ctx, path, limit)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.status, resp.body, nil
+}
+
+type ProbeOutput struct {
+	Findings []db.Finding
+	res      *result
+}
+
+// Add reports a finding, value may be anything json encodable, e.g. a string or a struct
+func (out *ProbeOutput) Add(kind string, severity db.Severity, value any) {
+	out.Findings = append(out.Findings, db.Finding{Kind: kind, Severity: severity, Value: value})
+}
+
+// probes are run in the order of registration
+var probes = []Probe{metadataProbe{}, spamProbe{}, fingerprintProbe{}, auditProbe{}}
+
+// RegisterProbe adds the probe to the ones run by checkers, it must be called before checkers are started
+func RegisterProbe(p Probe) {
+	if slices.Contains(ProbeNames(), p.Name()) {
+		panic(fmt.Sprintf("probe %s is already registered", p.Name()))
+	}
+	probes = append(probes, p)
+}
+
+func ProbeNames() []string {
+	names := make([]string, len(probes))
+	for i, p := range probes {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+func (c *Checker) probeTimeout(p Probe) time.Duration {
+	if t := c.cfg.Probes[p.Name()].Timeout; t > 0 {
+		return t
+	}
+	return p.Timeout()
+}
+
+// probesTimeout returns for how long enabled probes may run in total
+func (c *Checker) probesTimeout() time.Duration {
+	var total time.Duration
+	for _, p := range probes {
+		if !c.cfg.Probes[p.Name()].Disabled {
+			total += c.probeTimeout(p)
+		}
+	}
+	return total
+}
+
+func (c *Checker) runProbes(ctx context.Context, in *ProbeInput, res *result) []db.ProbeResult {
+	results := make([]db.ProbeResult, 0, len(probes))
+	for _, p := range probes {
+		if c.cfg.Probes[p.Name()].Disabled {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		out := &ProbeOutput{res: res}
+		start := time.Now()
+		probeCtx, cancel := context.WithTimeout(ctx, c.probeTimeout(p))
+		err := p.Run(probeCtx, in, out)
+		cancel()
+		probeRes := db.ProbeResult{
+			Probe:    p.Name(),
+			Findings: out.Findings,
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			probeRes.Error = err.Error()
+		}
+		results = append(results, probeRes)
+	}
+	return results
+}
+
+// metadataProbe extracts the title and detects language and categories of the site
+type metadataProbe struct{}
+
+func (metadataProbe) Name() string           { return "metadata" }
+func (metadataProbe) Timeout() time.Duration { return localProbeTimeout }
+
+func (metadataProbe) Run(ctx context.Context, in *ProbeInput, out *ProbeOutput) error {
+	res := out.res
+	res.Title = in.root.doc.title
+	res.Lang, res.Categories = in.checker.classify(in.root, res.pages)
+	res.HasMetadata = true
+	if res.Title != "" {
+		out.Add("title", db.SeverityInfo, res.Title)
+	}
+	if res.Lang != "" {
+		out.Add("lang", db.SeverityInfo, res.Lang)
+	}
+	for _, category := range res.Categories {
+		out.Add("category", db.SeverityInfo, category)
+	}
+	return nil
+}
+
+// spamProbe matches the main page against the spam rules
+type spamProbe struct{}
+
+func (spamProbe) Name() string           { return "spam" }
+func (spamProbe) Timeout() time.Duration { return localProbeTimeout }
+
+func (spamProbe) Run(ctx context.Context, in *ProbeInput, out *ProbeOutput) error {
+	// only the meta refresh is matched by the rules, a javascript redirect must not excuse it
+	redir, ok := in.checker.resolveRedirect(in.Domain, in.root.path, in.root.doc.refresh)
+	siteRefresh := ok && redir.kind == db.RedirectSite
+	for _, rule := range matchSpamRules(in.Body, siteRefresh) {
+		out.Add("rule", db.SeverityWarning, rule)
+	}
+	out.res.SpamContent = len(out.Findings) > 0
+	out.res.HasSpam = true
+	return nil
+}
+
+// fingerprintProbe detects the software serving the site
+type fingerprintProbe struct{}
+
+func (fingerprintProbe) Name() string           { return "fingerprint" }
+func (fingerprintProbe) Timeout() time.Duration { return localProbeTimeout }
+
+func (fingerprintProbe) Run(ctx context.Context, in *ProbeInput, out *ProbeOutput) error {
+	res := out.res
+	res.Server = headerValue(in.main.headers, "Server")
+	res.PoweredBy = headerValue(in.main.headers, "X-Powered-By")
+	res.Tech = fingerprint(in.main, in.root.doc)
+	res.HasFingerprint = true
+	if res.Server != "" {
+		out.Add("server", db.SeverityInfo, res.Server)
+	}
+	if res.PoweredBy != "" {
+		out.Add("poweredBy", db.SeverityInfo, res.PoweredBy)
+	}
+	for _, tech := range res.Tech {
+		out.Add("tech", db.SeverityInfo, tech)
+	}
+	return nil
+}
+
+// auditProbe looks for broken internal links and missing assets
+type auditProbe struct{}
+
+type auditFinding struct {
+	Target string `json:"target"`
+	Status int    `json:"status"`
+}
+
+func (auditProbe) Name() string           { return "audit" }
+func (auditProbe) Timeout() time.Duration { return auditTimeout }
+
+func (auditProbe) Run(ctx context.Context, in *ProbeInput, out *ProbeOutput) error {
+	issues := audit(ctx, in.site, in.pages, in.robots)
+	for _, issue := range issues {
+		kind := "brokenLink"
+		if issue.Kind == db.AuditAsset {
+			kind = "missingAsset"
+		}
+		out.Add(kind, db.SeverityWarning, auditFinding{Target: issue.Target, Status: issue.Status})
+	}
+	out.res.issues = issues
+	out.res.audited = true
+	return nil
+}
@@ -0,0 +1,174 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+const maxRedirects = 5
+
+// maxJsRedirectMatches limits location redirects examined per script
+const maxJsRedirectMatches = 16
+
+// jsRedirectRules match assignments to location in inline scripts, the first group is the target
+var jsRedirectRules = []*regexp.Regexp{
+	regexp.MustCompile(`(?:\b(?:window|document|top|self)\.)?\blocation(?:\.href)?\s*=\s*["'` + "`" + `]([^"'` + "`" + `]+)["'` + "`" + `]`),
+	regexp.MustCompile(`\blocation\.(?:replace|assign)\(\s*["'` + "`" + `]([^"'` + "`" + `]+)["'` + "`" + `]\s*\)`),
+}
+
+type redirect struct {
+	kind   db.RedirectKind
+	target string
+}
+
+// fetch requests the path following redirects within the site. redirects leaving the site are not
+// followed, the redirect response is returned along with its target
+func (c *Checker) fetch(ctx context.Context, s *site, path string, limit int64) (*response, redirect, error) {
+	var res redirect
+	current := &url.URL{Scheme: "http", Host: s.domain, Path: path}
+	for hop := 0; ; hop++ {
+		resp, err := s.get(ctx, requestPath(current), limit)
+		if err != nil {
+			return nil, res, err
+		}
+		resp.path = requestPath(current)
+		if !isRedirect(resp.status) {
+			return resp, res, nil
+		}
+		location := headerValue(resp.headers, "Location")
+		if location == "" {
+			return nil, res, fmt.Errorf("responded with redirect status code %d without location", resp.status)
+		}
+		if hop >= maxRedirects {
+			return nil, res, fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		ref, err := url.Parse(strings.TrimSpace(location))
+		if err != nil {
+			return nil, res, fmt.Errorf("invalid redirect location: %w", err)
+		}
+		next := current.ResolveReference(ref)
+		if (next.Scheme == "http" || next.Scheme == "https") && strings.EqualFold(next.Hostname(), s.domain) {
+			next.Fragment = ""
+			res = redirect{kind: db.RedirectSite, target: next.String()}
+			current = next
+			continue
+		}
+		return resp, redirect{kind: c.redirectKind(s.domain, next), target: next.String()}, nil
+	}
+}
+
+// redirectKind tells whether the target is another TON site or a clearnet one
+func (c *Checker) redirectKind(domain string, target *url.URL) db.RedirectKind {
+	if strings.EqualFold(target.Hostname(), domain) {
+		return db.RedirectSite
+	}
+	if _, _, ok := c.linkTarget(target.String()); ok {
+		return db.RedirectTon
+	}
+	return db.RedirectClearnet
+}
+
+// htmlRedirect returns the target of a meta refresh or a javascript location redirect of the page
+func (c *Checker) htmlRedirect(domain string, base string, doc *document) (redirect, bool) {
+	return c.resolveRedirect(domain, base, doc.redirect)
+}
+
+// resolveRedirect resolves the redirect target of a page against its path
+func (c *Checker) resolveRedirect(domain string, base string, location string) (redirect, bool) {
+	if location == "" {
+		return redirect{}, false
+	}
+	ref, err := url.Parse(strings.TrimSpace(location))
+	if err != nil {
+		return redirect{}, false
+	}
+	target := (&url.URL{Scheme: "http", Host: domain, Path: base}).ResolveReference(ref)
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return redirect{}, false
+	}
+	target.Fragment = ""
+	return redirect{kind: c.redirectKind(domain, target), target: target.String()}, true
+}
+
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// parseRefresh extracts the url from the content of a meta refresh, e.g. "0; url=/index.html"
+func parseRefresh(content string) string {
+	_, rest, found := strings.Cut(content, ";")
+	if !found {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(content), 64); err == nil {
+			return ""
+		}
+		rest = content
+	}
+	rest = strings.TrimSpace(rest)
+	if len(rest) >= 4 && strings.EqualFold(rest[:3], "url") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(rest[3:]), "="); ok {
+			rest = strings.TrimSpace(value)
+		}
+	}
+	return strings.Trim(rest, `"'`)
+}
+
+// parseJsRedirect returns the target of the first location redirect outside of any block,
+// assignments in functions and event handlers may never run on load
+func parseJsRedirect(script []byte) string {
+	var matches [][]int
+	for _, rule := range jsRedirectRules {
+		matches = append(matches, rule.FindAllSubmatchIndex(script, maxJsRedirectMatches)...)
+	}
+	slices.SortFunc(matches, func(a, b []int) int { return a[0] - b[0] })
+	// the matches are checked in order, so the script is scanned once
+	var sc scriptScanner
+	for _, m := range matches[:min(len(matches), maxJsRedirectMatches)] {
+		if sc.advance(script, m[0]) {
+			return string(script[m[2]:m[3]])
+		}
+	}
+	return ""
+}
+
+// scriptScanner tracks the brace depth of a script. braces in string literals are skipped,
+// comments and regular expressions are not recognized which is fine for a heuristic
+type scriptScanner struct {
+	pos   int
+	depth int
+	quote byte
+}
+
+// advance scans the script up to the position and reports whether it is outside of braces
+func (sc *scriptScanner) advance(script []byte, pos int) bool {
+	for ; sc.pos < pos; sc.pos++ {
+		ch := script[sc.pos]
+		switch {
+		case sc.quote != 0:
+			if ch == '\\' {
+				sc.pos++
+			} else if ch == sc.quote {
+				sc.quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			sc.quote = ch
+		case ch == '{':
+			sc.depth++
+		case ch == '}':
+			sc.depth--
+		}
+	}
+	return sc.depth <= 0
+}
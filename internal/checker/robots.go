@@ -0,0 +1,201 @@
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+// UserAgent is sent with every request the checker makes to TON sites
+const UserAgent = "Mozilla/5.0 (compatible; oxylume/1.0; +https://github.com/oxylume/index)"
+
+// robotsAgent is the product token site operators can use in robots.txt to address our indexer
+const robotsAgent = "oxylume"
+
+const maxRobotsSize = 512 << 10
+const robotsTTL = 24 * time.Hour
+const maxRobotsCache = 1 << 14
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+type robots struct {
+	rules    []robotsRule
+	sitemaps []string
+	found    bool
+	// unknown means robots.txt could not be fetched, so its policy is not known
+	unknown bool
+}
+
+// allowed reports whether the path may be crawled. the most specific (longest) matching rule wins,
+// allow wins if the matching allow and disallow rules are equally specific
+func (r *robots) allowed(path string) bool {
+	matched := -1
+	allow := true
+	for _, rule := range r.rules {
+		if !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > matched || (len(rule.pattern) == matched && rule.allow) {
+			matched = len(rule.pattern)
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// policy returns nil if robots.txt could not be fetched, the stored policy is kept then
+func (r *robots) policy() *db.RobotsPolicy {
+	policy := db.RobotsAllowed
+	switch {
+	case r.unknown:
+		return nil
+	case !r.found:
+		policy = db.RobotsNone
+	case !r.allowed("/"):
+		policy = db.RobotsDisallowed
+	default:
+		for _, rule := range r.rules {
+			if !rule.allow {
+				policy = db.RobotsPartial
+				break
+			}
+		}
+	}
+	return &policy
+}
+
+var allowAll = &robots{}
+
+// unreachableRobots blocks deep crawling of sites which robots.txt could not be fetched
+var unreachableRobots = &robots{
+	rules:   []robotsRule{{allow: false, pattern: "/"}},
+	unknown: true,
+}
+
+// parseRobots parses robots.txt as described by RFC 9309 keeping only the rules of the group
+// addressed to agent, or of the "*" group if there's no such one
+func parseRobots(data []byte, agent string) *robots {
+	res := &robots{found: true}
+	var own, common []robotsRule
+	var hasOwn bool
+	var groupOwn, groupAny, inRules bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if inRules {
+				groupOwn, groupAny, inRules = false, false, false
+			}
+			name := strings.ToLower(value)
+			if name == "*" {
+				groupAny = true
+			} else if name == agent {
+				groupOwn = true
+				hasOwn = true
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// empty disallow means allow everything and empty allow means nothing
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value}
+			if groupOwn {
+				own = append(own, rule)
+			}
+			if groupAny {
+				common = append(common, rule)
+			}
+		case "sitemap":
+			if value != "" {
+				res.sitemaps = append(res.sitemaps, value)
+			}
+		}
+	}
+	if hasOwn {
+		res.rules = own
+	} else {
+		res.rules = common
+	}
+	return res
+}
+
+// matchRobotsPattern matches path against the pattern supporting "*" wildcards and "$" end anchors.
+// on a mismatch it only backtracks to the last "*", so hostile patterns with many wildcards
+// take at most len(pattern)*len(path) steps
+func matchRobotsPattern(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+	p, i := 0, 0
+	star, mark := -1, 0
+	for i < len(path) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, i
+			p++
+		case p < len(pattern) && pattern[p] == path[i]:
+			p++
+			i++
+		case p == len(pattern) && !anchored:
+			// rules match path prefixes
+			return true
+		case star >= 0:
+			mark++
+			p, i = star+1, mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// robotsKey includes the site id, so robots.txt is fetched again once the domain points to another site
+type robotsKey struct {
+	domain string
+	id     string
+}
+
+// getRobots returns robots.txt rules of the site. missing robots.txt allows everything,
+// while unreachable one disallows deep crawling until the next check
+func (c *Checker) getRobots(ctx context.Context, s *site) *robots {
+	key := robotsKey{domain: s.domain, id: string(s.id)}
+	if cached, ok := c.robots.Get(key); ok {
+		return cached
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// redirects within the site are followed as RFC 9309 requires, ones leaving the site can't be
+	resp, _, err := c.fetch(ctx, s, "/robots.txt", maxRobotsSize)
+	if err != nil || resp.status >= http.StatusInternalServerError {
+		return unreachableRobots
+	}
+	var res *robots
+	if resp.status >= http.StatusOK && resp.status < http.StatusMultipleChoices {
+		res = parseRobots(resp.body, robotsAgent)
+	} else {
+		res = allowAll
+	}
+	c.robots.Set(key, res)
+	return res
+}
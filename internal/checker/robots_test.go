@@ -0,0 +1,134 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchRobotsPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"", "/anything", true},
+		{"/", "/", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish", false},
+		{"/fish/", "/fish", false},
+		{"/*.php", "/index.php", true},
+		{"/*.php", "/dir/index.php?a=1", true},
+		{"/*.php", "/index.html", false},
+		{"/*.php$", "/index.php", true},
+		{"/*.php$", "/index.php?a=1", false},
+		{"/fish*", "/fish", true},
+		{"/a*b*c", "/aXbYc", true},
+		{"/a*b*c$", "/aXbYcZ", false},
+		{"/a*b*c$", "/abcbc", true},
+		{"/*a*b", "/ccab", true},
+		{"*", "", true},
+		{"$", "", true},
+		{"/$", "/", true},
+		{"/$", "/page", false},
+		{"/**x", "/ax", true},
+	}
+	for _, tt := range tests {
+		if got := matchRobotsPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchRobotsPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatchRobotsPatternManyWildcards(t *testing.T) {
+	pattern := "/" + strings.Repeat("*a", 200) + "b"
+	path := "/" + strings.Repeat("a", 100_000)
+	start := time.Now()
+	if matchRobotsPattern(pattern, path) {
+		t.Fatal("pattern must not match")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("matching took %s", elapsed)
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		path string
+		want bool
+	}{
+		{
+			name: "longest match wins",
+			data: "User-agent: *\nDisallow: /private\nAllow: /private/public",
+			path: "/private/public/page",
+			want: true,
+		},
+		{
+			name: "longer disallow wins over allow",
+			data: "User-agent: *\nAllow: /docs\nDisallow: /docs/drafts",
+			path: "/docs/drafts/1",
+			want: false,
+		},
+		{
+			name: "allow wins on a tie",
+			data: "User-agent: *\nDisallow: /page\nAllow: /page",
+			path: "/page",
+			want: true,
+		},
+		{
+			name: "own group replaces the common one",
+			data: "User-agent: *\nDisallow: /\n\nUser-agent: oxylume\nDisallow: /admin",
+			path: "/blog",
+			want: true,
+		},
+		{
+			name: "own group rules apply",
+			data: "User-agent: *\nDisallow: /\n\nUser-agent: oxylume\nDisallow: /admin",
+			path: "/admin/login",
+			want: false,
+		},
+		{
+			name: "common group applies without own group",
+			data: "User-agent: otherbot\nAllow: /\n\nUser-agent: *\nDisallow: /",
+			path: "/blog",
+			want: false,
+		},
+		{
+			name: "grouped user agents share rules",
+			data: "User-agent: otherbot\nUser-agent: oxylume\nDisallow: /tmp",
+			path: "/tmp/file",
+			want: false,
+		},
+		{
+			name: "empty disallow allows everything",
+			data: "User-agent: *\nDisallow:",
+			path: "/",
+			want: true,
+		},
+		{
+			name: "comments are ignored",
+			data: "User-agent: * # everyone\nDisallow: /secret # hidden",
+			path: "/secret",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := parseRobots([]byte(tt.data), robotsAgent)
+			if got := r.allowed(tt.path); got != tt.want {
+				t.Errorf("allowed(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRobotsPolicy(t *testing.T) {
+	if p := unreachableRobots.policy(); p != nil {
+		t.Errorf("policy of unreachable robots.txt = %v, want nil", *p)
+	}
+	if unreachableRobots.allowed("/page") {
+		t.Error("unreachable robots.txt must disallow deep crawling")
+	}
+}
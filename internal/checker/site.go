@@ -0,0 +1,133 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/oxylume/index/pkg/proxy"
+)
+
+// site fetches resources of a single TON site regardless of whether it's served from a bag or over RLDP
+type site struct {
+	domain    string
+	id        []byte
+	inStorage bool
+	bag       *proxy.Bag
+	conn      *proxy.RLDPConnection
+}
+
+type response struct {
+	// path is the requested path, it differs from the original one if redirects were followed
+	path    string
+	status  int
+	headers []proxy.Header
+	body    []byte
+}
+
+func (c *Checker) openSite(ctx context.Context, domain string, id []byte, inStorage bool) (*site, error) {
+	s := &site{
+		domain:    domain,
+		id:        id,
+		inStorage: inStorage,
+	}
+	var err error
+	if inStorage {
+		s.bag, err = c.bags.GetBag(ctx, id)
+	} else {
+		s.conn, err = c.rldp.GetConnection(ctx, id)
+		c.dhtErrors.add(isDHTFailure(err))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// get requests the path and reads at most limit bytes of the response body
+func (s *site) get(ctx context.Context, path string, limit int64) (*response, error) {
+	if s.inStorage {
+		return s.getFile(ctx, path, limit)
+	}
+	return s.request(ctx, http.MethodGet, path, limit)
+}
+
+// head checks whether the path exists without downloading it, bags are checked against their file list
+func (s *site) head(ctx context.Context, path string) (*response, error) {
+	if s.inStorage {
+		if _, err := s.bag.GetFileOffsets(bagFileName(path)); err != nil {
+			return &response{status: http.StatusNotFound}, nil
+		}
+		return &response{status: http.StatusOK}, nil
+	}
+	resp, err := s.request(ctx, http.MethodHead, path, 0)
+	if err != nil {
+		return nil, err
+	}
+	// some servers do not implement HEAD, ask for the resource itself without reading it
+	if resp.status == http.StatusMethodNotAllowed || resp.status == http.StatusNotImplemented {
+		return s.request(ctx, http.MethodGet, path, 0)
+	}
+	return resp, nil
+}
+
+func (s *site) request(ctx context.Context, method string, path string, limit int64) (*response, error) {
+	req := &proxy.Request{
+		Method:  method,
+		Url:     fmt.Sprintf("http://%s%s", s.domain, path),
+		Version: "HTTP/1.1",
+		Headers: []proxy.Header{
+			{Name: "Host", Value: s.domain},
+			{Name: "User-Agent", Value: UserAgent},
+		},
+	}
+	resp, body, err := s.conn.SendRequest(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	res := &response{
+		status:  int(resp.StatusCode),
+		headers: resp.Headers,
+	}
+	if resp.NoPayload || limit == 0 {
+		return res, nil
+	}
+	res.body, err = io.ReadAll(io.LimitReader(body, limit))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *site) getFile(ctx context.Context, path string, limit int64) (*response, error) {
+	name := bagFileName(path)
+	info, err := s.bag.GetFileOffsets(name)
+	if err != nil {
+		return &response{status: http.StatusNotFound}, nil
+	}
+	res := &response{status: http.StatusOK}
+	size := min(info.Size, uint64(limit))
+	if size == 0 {
+		return res, nil
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	if err := s.bag.WriteFileTo(ctx, buf, info, 0, size-1, 1); err != nil {
+		return nil, err
+	}
+	res.body = buf.Bytes()
+	return res, nil
+}
+
+// bagFileName maps url path to the file name inside a bag the same way the gateway does
+func bagFileName(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	path, _, _ = strings.Cut(path, "#")
+	name := strings.TrimPrefix(path, "/")
+	if name == "" || strings.HasSuffix(name, "/") {
+		name += "index.html"
+	}
+	return name
+}
@@ -0,0 +1,152 @@
+package checker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+const maxSitemapSize = 10 << 20
+const maxSitemaps = 8
+const maxSitemapUrls = 5000
+
+var lastmodLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+}
+
+type sitemapXml struct {
+	Urls []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// getSitemap collects page urls of the site from sitemaps listed in robots.txt or from /sitemap.xml.
+// sitemap indexes are followed, but the number of sitemaps, their size and the number of urls are bounded
+func (c *Checker) getSitemap(ctx context.Context, s *site, robots *robots) []db.SitemapEntry {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	queue := make([]string, 0, len(robots.sitemaps))
+	for _, loc := range robots.sitemaps {
+		if path, ok := sitePath(s.domain, loc); ok {
+			queue = append(queue, path)
+		}
+	}
+	if len(queue) == 0 {
+		queue = append(queue, "/sitemap.xml")
+	}
+	visited := make(map[string]struct{})
+	seen := make(map[string]struct{})
+	entries := make([]db.SitemapEntry, 0)
+	for fetched := 0; len(queue) > 0 && fetched < maxSitemaps; fetched++ {
+		path := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[path]; ok {
+			continue
+		}
+		visited[path] = struct{}{}
+
+		parsed, err := fetchSitemap(ctx, s, path)
+		if err != nil {
+			continue
+		}
+		for _, child := range parsed.Sitemaps {
+			if path, ok := sitePath(s.domain, child.Loc); ok {
+				queue = append(queue, path)
+			}
+		}
+		for _, item := range parsed.Urls {
+			if len(entries) >= maxSitemapUrls {
+				return entries
+			}
+			path, ok := sitePath(s.domain, item.Loc)
+			if !ok || !robots.allowed(path) {
+				continue
+			}
+			if _, ok := seen[path]; ok {
+				continue
+			}
+			seen[path] = struct{}{}
+			entries = append(entries, db.SitemapEntry{
+				Path:    path,
+				LastMod: parseLastmod(item.LastMod),
+			})
+		}
+	}
+	return entries
+}
+
+func fetchSitemap(ctx context.Context, s *site, path string) (*sitemapXml, error) {
+	resp, err := s.get(ctx, path, maxSitemapSize)
+	if err != nil {
+		return nil, err
+	}
+	if resp.status != http.StatusOK {
+		return nil, fmt.Errorf("responded with non-ok status code %d", resp.status)
+	}
+	var reader io.Reader = bytes.NewReader(resp.body)
+	// gzipped sitemaps are served both with .gz extension and without one
+	if bytes.HasPrefix(resp.body, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = io.LimitReader(gz, maxSitemapSize)
+	}
+	var parsed sitemapXml
+	if err := xml.NewDecoder(reader).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// sitePath returns path with query of the url if it points to the same site
+func sitePath(domain string, loc string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(loc))
+	if err != nil {
+		return "", false
+	}
+	if u.Host != "" && !strings.EqualFold(u.Hostname(), domain) {
+		return "", false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	path := u.EscapedPath()
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path, true
+}
+
+func parseLastmod(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range lastmodLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
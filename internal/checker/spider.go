@@ -0,0 +1,167 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+type SpiderConfig struct {
+	Enabled  bool
+	MaxDepth int
+	MaxPages int
+	MaxBytes int64
+}
+
+var pageExts = map[string]struct{}{
+	"":      {},
+	".html": {},
+	".htm":  {},
+	".php":  {},
+}
+
+type spiderItem struct {
+	path  string
+	depth int
+}
+
+// crawledPage is a fetched page of the site along with its parsed document
+type crawledPage struct {
+	path   string
+	status int
+	doc    *document
+}
+
+// spider crawls internal pages of the site starting from the already fetched root page and sitemap urls.
+// bags are enumerated using the file listing, RLDP sites are crawled by following internal links
+func (c *Checker) spider(ctx context.Context, s *site, root crawledPage, robots *robots, sitemap []db.SitemapEntry) []crawledPage {
+	cfg := c.cfg.Spider
+	ctx, cancel := context.WithTimeout(ctx, spiderTimeout)
+	defer cancel()
+
+	pages := []crawledPage{root}
+	visited := map[string]struct{}{"/": {}, root.path: {}}
+	queue := make([]spiderItem, 0)
+	enqueue := func(paths []string, depth int) {
+		for _, p := range paths {
+			if _, ok := visited[p]; ok {
+				continue
+			}
+			if !robots.allowed(p) {
+				continue
+			}
+			visited[p] = struct{}{}
+			queue = append(queue, spiderItem{path: p, depth: depth})
+		}
+	}
+	sitemapPaths := make([]string, len(sitemap))
+	for i, entry := range sitemap {
+		sitemapPaths[i] = entry.Path
+	}
+	enqueue(sitemapPaths, 1)
+	if s.inStorage {
+		enqueue(bagPages(s, cfg.MaxDepth), 0)
+	} else {
+		enqueue(internalLinks(s.domain, root.path, root.doc.links), 1)
+	}
+
+	budget := cfg.MaxBytes
+	for len(queue) > 0 && len(pages) < cfg.MaxPages && budget > 0 {
+		if ctx.Err() != nil {
+			break
+		}
+		item := queue[0]
+		queue = queue[1:]
+		resp, err := s.get(ctx, item.path, min(budget, maxPageSize))
+		if err != nil {
+			continue
+		}
+		budget -= int64(len(resp.body))
+		doc := parseDocument(resp.body)
+		pages = append(pages, crawledPage{path: item.path, status: resp.status, doc: doc})
+		if !s.inStorage && item.depth < cfg.MaxDepth && resp.status == http.StatusOK {
+			enqueue(internalLinks(s.domain, item.path, doc.links), item.depth+1)
+		}
+	}
+	return pages
+}
+
+func newPage(page crawledPage) db.Page {
+	return db.Page{
+		Path:   page.path,
+		Status: page.status,
+		Title:  page.doc.title,
+		Text:   page.doc.text,
+	}
+}
+
+// bagPages lists html files of the bag that are not nested deeper than maxDepth directories
+func bagPages(s *site, maxDepth int) []string {
+	files, err := s.bag.ListFiles()
+	if err != nil {
+		return nil
+	}
+	paths := make([]string, 0)
+	for _, name := range files {
+		if name == "index.html" || strings.Count(name, "/") > maxDepth {
+			continue
+		}
+		ext := strings.ToLower(path.Ext(name))
+		if ext != ".html" && ext != ".htm" {
+			continue
+		}
+		paths = append(paths, "/"+name)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// internalLinks resolves links found on the page at base path and returns paths that belong to the same site
+func internalLinks(domain string, base string, links []string) []string {
+	paths := make([]string, 0)
+	for _, link := range internalRefs(domain, base, links) {
+		if _, ok := pageExts[strings.ToLower(path.Ext(link.Path))]; !ok {
+			continue
+		}
+		paths = append(paths, requestPath(link))
+	}
+	return paths
+}
+
+// internalRefs resolves references found on the page at base path and returns ones that point to the same site
+func internalRefs(domain string, base string, refs []string) []*url.URL {
+	baseUrl := &url.URL{Scheme: "http", Host: domain, Path: base}
+	res := make([]*url.URL, 0)
+	for _, ref := range refs {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			continue
+		}
+		u = baseUrl.ResolveReference(u)
+		if u.Scheme != "http" && u.Scheme != "https" {
+			continue
+		}
+		if !strings.EqualFold(u.Hostname(), domain) {
+			continue
+		}
+		res = append(res, u)
+	}
+	return res
+}
+
+// requestPath returns the path with the query of the url as it's sent to the site
+func requestPath(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		p = "/"
+	}
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	return p
+}
@@ -76,6 +76,9 @@ func (c *Crawler) worker(ctx context.Context, src *DomainSource, offset int) {
 			continue
 		}
 		if len(nfts) == 0 {
+			if err := c.state.SetSynced(ctx, srcAddr); err != nil {
+				log.Printf("[CRAWLER] unable to save sync time: %v", err)
+			}
 			select {
 			case <-ctx.Done():
 				return
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditKind int
+
+const (
+	AuditLink AuditKind = iota
+	AuditAsset
+)
+
+// AuditIssue is a missing internal link or asset, Source is the page that references the Target
+type AuditIssue struct {
+	Source string
+	Target string
+	Kind   AuditKind
+	Status int
+}
+
+type AuditStore struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditStore(db *pgxpool.Pool) *AuditStore {
+	return &AuditStore{
+		db: db,
+	}
+}
+
+// SetIssues replaces audit issues of the site
+func (r *AuditStore) SetIssues(ctx context.Context, domain string, issues []AuditIssue) error {
+	const deleteSql = `
+	delete from audit_issues
+	where domain = $1
+	`
+	const insertSql = `
+	insert into audit_issues (domain, target, source, kind, status)
+	select $1, * from unnest($2::text[], $3::text[], $4::int[], $5::int[])
+	on conflict do nothing
+	`
+	targets := make([]string, len(issues))
+	sources := make([]string, len(issues))
+	kinds := make([]AuditKind, len(issues))
+	statuses := make([]int, len(issues))
+	for i, issue := range issues {
+		targets[i] = issue.Target
+		sources[i] = issue.Source
+		kinds[i] = issue.Kind
+		statuses[i] = issue.Status
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, deleteSql, domain); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, insertSql, domain, targets, sources, kinds, statuses); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *AuditStore) List(ctx context.Context, domain string, after string, limit int) ([]AuditIssue, error) {
+	const sql = `
+	select source, target, kind, status from audit_issues
+	where domain = $1 and target > $2
+	order by target asc
+	limit $3
+	`
+	rows, err := r.db.Query(ctx, sql, domain, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (AuditIssue, error) {
+		var i AuditIssue
+		err := row.Scan(&i.Source, &i.Target, &i.Kind, &i.Status)
+		return i, err
+	})
+}
+
+// Count returns the number of broken links and missing assets of the site
+func (r *AuditStore) Count(ctx context.Context, domain string) (links int, assets int, err error) {
+	const sql = `
+	select
+		count(*) filter (where kind = $2),
+		count(*) filter (where kind = $3)
+	from audit_issues
+	where domain = $1
+	`
+	err = r.db.QueryRow(ctx, sql, domain, AuditLink, AuditAsset).Scan(&links, &assets)
+	return links, assets, err
+}
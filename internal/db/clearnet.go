@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ClearnetDep struct {
+	Url  string
+	Host string
+	Kind string
+}
+
+type ClearnetStore struct {
+	db *pgxpool.Pool
+}
+
+func NewClearnetStore(db *pgxpool.Pool) *ClearnetStore {
+	return &ClearnetStore{
+		db: db,
+	}
+}
+
+// SetDeps replaces clearnet resources referenced by the site and updates its dependency counter
+func (r *ClearnetStore) SetDeps(ctx context.Context, domain string, deps []ClearnetDep) error {
+	const deleteSql = `
+	delete from clearnet_deps
+	where domain = $1
+	`
+	const insertSql = `
+	insert into clearnet_deps (domain, url, host, kind)
+	select $1, * from unnest($2::text[], $3::text[], $4::text[])
+	on conflict do nothing
+	`
+	const updateSql = `
+	update sites set clearnet_deps = $2
+	where domain = $1
+	`
+	urls := make([]string, len(deps))
+	hosts := make([]string, len(deps))
+	kinds := make([]string, len(deps))
+	for i, dep := range deps {
+		urls[i] = dep.Url
+		hosts[i] = dep.Host
+		kinds[i] = dep.Kind
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, deleteSql, domain); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, insertSql, domain, urls, hosts, kinds); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, updateSql, domain, len(deps)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *ClearnetStore) List(ctx context.Context, domain string, after string, limit int) ([]ClearnetDep, error) {
+	const sql = `
+	select url, host, kind from clearnet_deps
+	where domain = $1 and url > $2
+	order by url asc
+	limit $3
+	`
+	rows, err := r.db.Query(ctx, sql, domain, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ClearnetDep, error) {
+		var d ClearnetDep
+		err := row.Scan(&d.Url, &d.Host, &d.Kind)
+		return d, err
+	})
+}
+
+// CountHosts returns the number of resources the site loads from each clearnet host
+func (r *ClearnetStore) CountHosts(ctx context.Context, domain string) (map[string]int, error) {
+	const sql = `
+	select host, count(*) from clearnet_deps
+	where domain = $1
+	group by host
+	`
+	rows, err := r.db.Query(ctx, sql, domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make(map[string]int)
+	for rows.Next() {
+		var host string
+		var count int
+		if err := rows.Scan(&host, &count); err != nil {
+			return nil, err
+		}
+		res[host] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -41,3 +42,35 @@ func (r *CrawlerStore) SetOffset(ctx context.Context, dns string, offset int) er
 	_, err := r.db.Exec(ctx, sql, dns, offset)
 	return err
 }
+
+// CrawlerState is the progress of crawling a DNS collection
+type CrawlerState struct {
+	Offset int
+	// SyncedAt is when the crawler last reached the end of the collection, nil if it never did
+	SyncedAt *time.Time
+}
+
+func (r *CrawlerStore) GetState(ctx context.Context, dns string) (CrawlerState, error) {
+	const sql = `
+	select last_offset, synced_at from crawler_state
+	where dns = $1
+	`
+	var state CrawlerState
+	err := r.db.QueryRow(ctx, sql, dns).Scan(&state.Offset, &state.SyncedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return CrawlerState{}, nil
+	}
+	return state, err
+}
+
+// SetSynced records that the crawler has processed every item of the collection
+func (r *CrawlerStore) SetSynced(ctx context.Context, dns string) error {
+	const sql = `
+	insert into crawler_state (dns, synced_at)
+	values ($1, now())
+	on conflict (dns) do update set
+		synced_at = excluded.synced_at
+	`
+	_, err := r.db.Exec(ctx, sql, dns)
+	return err
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type Favicon struct {
+	Content     []byte
+	ContentType string
+	Hash        []byte
+	CheckedAt   time.Time
+}
+
+type FaviconsStore struct {
+	db *pgxpool.Pool
+}
+
+func NewFaviconsStore(db *pgxpool.Pool) *FaviconsStore {
+	return &FaviconsStore{
+		db: db,
+	}
+}
+
+// Set saves the favicon of the site, favicon without content marks the site as checked but missing an icon
+func (r *FaviconsStore) Set(ctx context.Context, domain string, favicon *Favicon) error {
+	const sql = `
+	insert into favicons (domain, content, content_type, hash, checked_at)
+	values ($1, $2, $3, $4, now())
+	on conflict (domain) do update set
+		content = excluded.content,
+		content_type = excluded.content_type,
+		hash = excluded.hash,
+		checked_at = excluded.checked_at
+	`
+	var content, hash []byte
+	if len(favicon.Content) > 0 {
+		sum := sha256.Sum256(favicon.Content)
+		content, hash = favicon.Content, sum[:]
+	}
+	_, err := r.db.Exec(ctx, sql, domain, content, favicon.ContentType, hash)
+	return err
+}
+
+// CheckedAt returns when the favicon of the site was looked up last time or zero time if never
+func (r *FaviconsStore) CheckedAt(ctx context.Context, domain string) (time.Time, error) {
+	const sql = `
+	select checked_at from favicons
+	where domain = $1
+	`
+	var checkedAt time.Time
+	err := r.db.QueryRow(ctx, sql, domain).Scan(&checkedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return checkedAt, err
+}
+
+func (r *FaviconsStore) Get(ctx context.Context, domain string) (*Favicon, error) {
+	const sql = `
+	select content, content_type, hash, checked_at from favicons
+	where domain = $1 and content is not null
+	`
+	var f Favicon
+	err := r.db.QueryRow(ctx, sql, domain).Scan(&f.Content, &f.ContentType, &f.Hash, &f.CheckedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
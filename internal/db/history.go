@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StatsPoint is a snapshot of site statistics, Sites - Storage of the sites are hosted over ADNL
+type StatsPoint struct {
+	At      time.Time
+	Domains int
+	Sites   int
+	Active  int
+	Storage int
+	Spam    int
+}
+
+type HistoryStore struct {
+	db *pgxpool.Pool
+}
+
+func NewHistoryStore(db *pgxpool.Pool) *HistoryStore {
+	return &HistoryStore{
+		db: db,
+	}
+}
+
+// Record saves the statistics of each zone as of the last SitesStore.RefreshStats
+func (r *HistoryStore) Record(ctx context.Context) error {
+	const sql = `
+	insert into stats_history (zone, recorded_at, domains, sites, active, storage, spam)
+	select zone, now(), domains, sites, active, storage, spam from site_stats
+	on conflict do nothing
+	`
+	_, err := r.db.Exec(ctx, sql)
+	return err
+}
+
+// Series returns statistics of the zone or of all zones if zone is empty recorded within [from, to).
+// snapshots are downsampled to one per step, the latest snapshot within each step is taken
+func (r *HistoryStore) Series(ctx context.Context, zone string, from time.Time, to time.Time, step time.Duration) ([]StatsPoint, error) {
+	const sql = `
+	with totals as (
+		select
+			recorded_at,
+			sum(domains)::int as domains,
+			sum(sites)::int as sites,
+			sum(active)::int as active,
+			sum(storage)::int as storage,
+			sum(spam)::int as spam
+		from stats_history
+		where recorded_at >= $1 and recorded_at < $2 and ($4 = '' or zone = $4)
+		group by recorded_at
+	)
+	select distinct on (bucket)
+		to_timestamp(extract(epoch from recorded_at)::bigint / $3::bigint * $3::bigint) as bucket,
+		domains, sites, active, storage, spam
+	from totals
+	order by bucket asc, recorded_at desc
+	`
+	rows, err := r.db.Query(ctx, sql, from, to, int64(step.Seconds()), zone)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (StatsPoint, error) {
+		var p StatsPoint
+		err := row.Scan(&p.At, &p.Domains, &p.Sites, &p.Active, &p.Storage, &p.Spam)
+		return p, err
+	})
+}
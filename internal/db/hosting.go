@@ -0,0 +1,215 @@
+package db
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Host is an udp address advertised in DHT by an ADNL site, location fields are empty without a GeoIP database
+type Host struct {
+	IP      netip.Addr
+	Port    int
+	Country string
+	Asn     int
+	Org     string
+}
+
+type AsnCount struct {
+	Asn   int
+	Org   string
+	Sites int
+}
+
+type SharedHost struct {
+	IP    netip.Addr
+	Sites int
+}
+
+type HostingStats struct {
+	// Sites is the number of accessible ADNL sites with known hosts
+	Sites       int
+	Countries   map[string]int
+	Asns        []AsnCount
+	SharedHosts []SharedHost
+	// Ipv4Addresses is the number of distinct ipv4 addresses used by Ipv4Sites sites, Ipv4Shared of them serve more than one site
+	Ipv4Addresses int
+	Ipv4Sites     int
+	Ipv4Shared    int
+}
+
+type HostingStore struct {
+	db *pgxpool.Pool
+}
+
+func NewHostingStore(db *pgxpool.Pool) *HostingStore {
+	return &HostingStore{
+		db: db,
+	}
+}
+
+// SetHosts replaces advertised addresses of the site
+func (r *HostingStore) SetHosts(ctx context.Context, domain string, hosts []Host) error {
+	const deleteSql = `
+	delete from site_hosts
+	where domain = $1
+	`
+	const insertSql = `
+	insert into site_hosts (domain, ip, port, country, asn, org)
+	select $1, ip::inet, port, country, asn, org
+	from unnest($2::text[], $3::int[], $4::text[], $5::int[], $6::text[]) as t(ip, port, country, asn, org)
+	on conflict do nothing
+	`
+	ips := make([]string, len(hosts))
+	ports := make([]int, len(hosts))
+	countries := make([]string, len(hosts))
+	asns := make([]int, len(hosts))
+	orgs := make([]string, len(hosts))
+	for i, host := range hosts {
+		ips[i] = host.IP.String()
+		ports[i] = host.Port
+		countries[i] = host.Country
+		asns[i] = host.Asn
+		orgs[i] = host.Org
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, deleteSql, domain); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, insertSql, domain, ips, ports, countries, asns, orgs); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *HostingStore) List(ctx context.Context, domain string) ([]Host, error) {
+	const sql = `
+	select host(ip), port, country, asn, org from site_hosts
+	where domain = $1
+	order by ip, port
+	`
+	rows, err := r.db.Query(ctx, sql, domain)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Host, error) {
+		var h Host
+		var ip string
+		if err := row.Scan(&ip, &h.Port, &h.Country, &h.Asn, &h.Org); err != nil {
+			return h, err
+		}
+		h.IP, err = netip.ParseAddr(ip)
+		return h, err
+	})
+}
+
+// CountShared returns the number of other accessible sites hosted on any of the addresses of the site
+func (r *HostingStore) CountShared(ctx context.Context, domain string) (int, error) {
+	const sql = `
+	select count(distinct other.domain) from site_hosts own
+	join site_hosts other on other.ip = own.ip and other.domain != own.domain
+	join sites s on s.domain = other.domain and s.status = $2
+	where own.domain = $1
+	`
+	var count int
+	err := r.db.QueryRow(ctx, sql, domain, StatusAccessible).Scan(&count)
+	return count, err
+}
+
+// GetStats aggregates hosts of accessible ADNL sites, limit bounds the number of returned asns and shared hosts
+func (r *HostingStore) GetStats(ctx context.Context, limit int) (*HostingStats, error) {
+	const hostsCte = `
+	with hosts as (
+		select h.domain, h.ip, h.country, h.asn, h.org from site_hosts h
+		join sites s on s.domain = h.domain
+		where s.status = $1
+	)
+	`
+	const totalsSql = hostsCte + `,
+	v4 as (
+		select ip, domain from hosts
+		where family(ip) = 4
+	)
+	select
+		(select count(distinct domain) from hosts),
+		(select count(distinct ip) from v4),
+		(select count(distinct domain) from v4),
+		(select count(*) from (select ip from v4 group by ip having count(distinct domain) > 1) t)
+	`
+	const countriesSql = hostsCte + `
+	select coalesce(nullif(country, ''), 'unknown'), count(distinct domain) from hosts
+	group by 1
+	`
+	const asnsSql = hostsCte + `
+	select asn, max(org), count(distinct domain) from hosts
+	where asn != 0
+	group by asn
+	order by 3 desc, asn asc
+	limit $2
+	`
+	const sharedSql = hostsCte + `
+	select host(ip), count(distinct domain) from hosts
+	group by ip
+	having count(distinct domain) > 1
+	order by 2 desc, ip asc
+	limit $2
+	`
+	var stats HostingStats
+	err := r.db.QueryRow(ctx, totalsSql, StatusAccessible).Scan(&stats.Sites, &stats.Ipv4Addresses, &stats.Ipv4Sites, &stats.Ipv4Shared)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, countriesSql, StatusAccessible)
+	if err != nil {
+		return nil, err
+	}
+	stats.Countries = make(map[string]int)
+	var country string
+	var count int
+	_, err = pgx.ForEachRow(rows, []any{&country, &count}, func() error {
+		stats.Countries[country] = count
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(ctx, asnsSql, StatusAccessible, limit)
+	if err != nil {
+		return nil, err
+	}
+	stats.Asns, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (AsnCount, error) {
+		var a AsnCount
+		err := row.Scan(&a.Asn, &a.Org, &a.Sites)
+		return a, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(ctx, sharedSql, StatusAccessible, limit)
+	if err != nil {
+		return nil, err
+	}
+	stats.SharedHosts, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (SharedHost, error) {
+		var h SharedHost
+		var ip string
+		if err := row.Scan(&ip, &h.Sites); err != nil {
+			return h, err
+		}
+		h.IP, err = netip.ParseAddr(ip)
+		return h, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LinksStore struct {
+	db *pgxpool.Pool
+}
+
+func NewLinksStore(db *pgxpool.Pool) *LinksStore {
+	return &LinksStore{
+		db: db,
+	}
+}
+
+// SetLinks replaces all outgoing links of the source site and refreshes backlink counters of affected sites
+func (r *LinksStore) SetLinks(ctx context.Context, source string, targets []string) error {
+	const deleteSql = `
+	delete from links
+	where source = $1
+	returning target
+	`
+	const insertSql = `
+	insert into links (source, target)
+	select $1, t from unnest($2::text[]) as t
+	where t != $1
+	on conflict do nothing
+	`
+	const countSql = `
+	update sites set
+		backlinks = (
+			select count(*) from links
+			where target = sites.domain
+		)
+	where domain = any($1)
+	`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, deleteSql, source)
+	if err != nil {
+		return err
+	}
+	old, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, insertSql, source, targets); err != nil {
+		return err
+	}
+	affected := append(old, targets...)
+	if _, err := tx.Exec(ctx, countSql, affected); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *LinksStore) ListBacklinks(ctx context.Context, target string, after string, limit int) ([]string, error) {
+	const sql = `
+	select source from links
+	where target = $1 and source > $2
+	order by source asc
+	limit $3
+	`
+	rows, err := r.db.Query(ctx, sql, target, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *LinksStore) ListOutlinks(ctx context.Context, source string, after string, limit int) ([]string, error) {
+	const sql = `
+	select target from links
+	where source = $1 and target > $2
+	order by target asc
+	limit $3
+	`
+	rows, err := r.db.Query(ctx, sql, source, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
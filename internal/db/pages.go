@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Page struct {
+	Path      string
+	Status    int
+	Title     string
+	Text      string
+	FetchedAt time.Time
+}
+
+type SitemapEntry struct {
+	Path      string
+	LastMod   *time.Time
+	ChangedAt time.Time
+}
+
+type PagesStore struct {
+	db *pgxpool.Pool
+}
+
+func NewPagesStore(db *pgxpool.Pool) *PagesStore {
+	return &PagesStore{
+		db: db,
+	}
+}
+
+// SetPages replaces all crawled pages of the site
+func (r *PagesStore) SetPages(ctx context.Context, domain string, pages []Page) error {
+	const deleteSql = `
+	delete from pages
+	where domain = $1
+	`
+	const insertSql = `
+	insert into pages (domain, path, status, title, content)
+	select $1, * from unnest($2::text[], $3::int[], $4::text[], $5::text[])
+	on conflict do nothing
+	`
+	paths := make([]string, len(pages))
+	statuses := make([]int, len(pages))
+	titles := make([]string, len(pages))
+	texts := make([]string, len(pages))
+	for i, page := range pages {
+		paths[i] = page.Path
+		statuses[i] = page.Status
+		titles[i] = page.Title
+		texts[i] = page.Text
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, deleteSql, domain); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, insertSql, domain, paths, statuses, titles, texts); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// List returns crawled pages of the site without their text content
+func (r *PagesStore) List(ctx context.Context, domain string, after string, limit int) ([]Page, error) {
+	const sql = `
+	select path, status, title, fetched_at from pages
+	where domain = $1 and path > $2
+	order by path asc
+	limit $3
+	`
+	rows, err := r.db.Query(ctx, sql, domain, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Page, error) {
+		var p Page
+		err := row.Scan(&p.Path, &p.Status, &p.Title, &p.FetchedAt)
+		return p, err
+	})
+}
+
+// SetSitemap replaces sitemap urls of the site. changed_at is bumped for new urls and urls with a new lastmod
+func (r *PagesStore) SetSitemap(ctx context.Context, domain string, entries []SitemapEntry) error {
+	const deleteSql = `
+	delete from sitemap_urls
+	where domain = $1 and not (path = any($2))
+	`
+	const upsertSql = `
+	insert into sitemap_urls (domain, path, lastmod)
+	select $1, * from unnest($2::text[], $3::timestamptz[])
+	on conflict (domain, path) do update set
+		lastmod = excluded.lastmod,
+		changed_at = case
+			when sitemap_urls.lastmod is distinct from excluded.lastmod then now()
+			else sitemap_urls.changed_at
+		end
+	`
+	paths := make([]string, len(entries))
+	lastmods := make([]*time.Time, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.Path
+		lastmods[i] = entry.LastMod
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, deleteSql, domain, paths); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, upsertSql, domain, paths, lastmods); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PagesStore) ListSitemap(ctx context.Context, domain string, after string, limit int) ([]SitemapEntry, error) {
+	const sql = `
+	select path, lastmod, changed_at from sitemap_urls
+	where domain = $1 and path > $2
+	order by path asc
+	limit $3
+	`
+	rows, err := r.db.Query(ctx, sql, domain, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (SitemapEntry, error) {
+		var e SitemapEntry
+		err := row.Scan(&e.Path, &e.LastMod, &e.ChangedAt)
+		return e, err
+	})
+}
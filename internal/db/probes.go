@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Severity tells how much a finding matters to the site operator
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Finding is a single observation a probe made about the site, Kind is specific to the probe
+// and Value is any json value, its shape is defined by the Kind
+type Finding struct {
+	Kind     string   `json:"kind"`
+	Severity Severity `json:"severity"`
+	Value    any      `json:"value"`
+}
+
+type ProbeResult struct {
+	Probe    string
+	Findings []Finding
+	// Error is empty if the probe completed successfully
+	Error     string
+	Duration  time.Duration
+	CheckedAt time.Time
+}
+
+type ProbesStore struct {
+	db *pgxpool.Pool
+}
+
+func NewProbesStore(db *pgxpool.Pool) *ProbesStore {
+	return &ProbesStore{
+		db: db,
+	}
+}
+
+// SetResults replaces the results of the probes that ran, results of the others are kept
+func (r *ProbesStore) SetResults(ctx context.Context, domain string, results []ProbeResult) error {
+	const deleteSql = `
+	delete from probe_results
+	where domain = $1 and probe = any($2::text[])
+	`
+	const insertSql = `
+	insert into probe_results (domain, probe, findings, error, duration_ms)
+	select $1, probe, findings::jsonb, error, duration_ms
+	from unnest($2::text[], $3::text[], $4::text[], $5::int[]) as r(probe, findings, error, duration_ms)
+	on conflict do nothing
+	`
+	probes := make([]string, len(results))
+	findings := make([]string, len(results))
+	errs := make([]string, len(results))
+	durations := make([]int64, len(results))
+	for i, res := range results {
+		items := res.Findings
+		if items == nil {
+			items = []Finding{}
+		}
+		data, err := json.Marshal(items)
+		if err != nil {
+			return err
+		}
+		probes[i] = res.Probe
+		findings[i] = string(data)
+		errs[i] = res.Error
+		durations[i] = res.Duration.Milliseconds()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, deleteSql, domain, probes); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, insertSql, domain, probes, findings, errs, durations); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *ProbesStore) List(ctx context.Context, domain string) ([]ProbeResult, error) {
+	const sql = `
+	select probe, findings, error, duration_ms, checked_at from probe_results
+	where domain = $1
+	order by probe asc
+	`
+	rows, err := r.db.Query(ctx, sql, domain)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ProbeResult, error) {
+		var res ProbeResult
+		var durationMs int64
+		err := row.Scan(&res.Probe, &res.Findings, &res.Error, &durationMs, &res.CheckedAt)
+		res.Duration = time.Duration(durationMs) * time.Millisecond
+		return res, err
+	})
+}
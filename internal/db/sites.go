@@ -2,10 +2,12 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -13,6 +15,15 @@ type Stats struct {
 	TotalDomains int
 	TotalSites   int
 	ActiveSites  int
+	Tech         map[string]int
+}
+
+type ZoneStats struct {
+	Domains int
+	Sites   int
+	Active  int
+	Storage int
+	Spam    int
 }
 
 type SiteStatus int
@@ -23,19 +34,56 @@ const (
 	StatusAccessible
 )
 
+type RobotsPolicy int
+
+const (
+	RobotsNone RobotsPolicy = iota
+	RobotsAllowed
+	RobotsPartial
+	RobotsDisallowed
+)
+
+type RedirectKind int
+
+const (
+	RedirectNone RedirectKind = iota
+	// RedirectSite is a redirect within the site itself, e.g. from / to /en/
+	RedirectSite
+	// RedirectTon is a redirect to another TON site
+	RedirectTon
+	// RedirectClearnet is a redirect leaving the TON network
+	RedirectClearnet
+)
+
 type SortBy string
 
 const (
 	SortByDomain    SortBy = "domain"
 	SortByCheckedAt SortBy = "checked_at"
+	SortByBacklinks SortBy = "backlinks"
+	SortByRank      SortBy = "rank"
+	SortByChangedAt SortBy = "content_changed_at"
 )
 
+// uptimeWeight is the weight of the latest check in the exponential moving average of uptime
+const uptimeWeight = 0.1
+
+const siteColumns = "domain, unicode, address, status, in_storage, spam_content, checked_at, backlinks, robots, lang, categories, rank, title, server, powered_by, tech, clearnet_deps, redirect, redirect_to, content_changed_at, bag_peers, bag_size, bag_files, bag_available, bag_health, site_id, wallet"
+
 type ListFilters struct {
-	Search       string
+	Terms        []string
+	ExcludeTerms []string
 	Inaccessible bool
 	Punycode     *bool
 	Spam         bool
 	Zone         string
+	Lang         string
+	Category     string
+	Tech         string
+	InStorage    *bool
+	// SelfContained filters sites by whether they load any resources from the clearnet
+	SelfContained *bool
+	Redirect      *RedirectKind
 
 	SortBy SortBy
 	Desc   bool
@@ -49,18 +97,98 @@ type SiteCreate struct {
 }
 
 type Site struct {
-	Domain      string
-	Unicode     string
-	Address     string
+	Domain       string
+	Unicode      string
+	Address      string
+	Status       SiteStatus
+	InStorage    bool
+	SpamContent  bool
+	CheckedAt    time.Time
+	Backlinks    int
+	Robots       RobotsPolicy
+	Lang         string
+	Categories   []string
+	Rank         float64
+	Title        string
+	Server       string
+	PoweredBy    string
+	Tech         []string
+	ClearnetDeps int
+	Redirect     RedirectKind
+	RedirectTo   string
+	// ContentChangedAt is when a new snapshot of the main page was archived last time
+	ContentChangedAt time.Time
+	// Bag is nil for sites hosted over ADNL
+	Bag *BagHealth
+	// SiteId is the ADNL address or bag id from the "TON Site" record, nil if the domain has none
+	SiteId []byte
+	// Wallet is the raw address from the "wallet" DNS record, empty if the domain has none
+	Wallet string
+}
+
+// BagHealth describes how well the bag of a site hosted in TON storage is seeded
+type BagHealth struct {
+	Peers int
+	Size  int64
+	Files int
+	// Available is the fraction of sampled pieces that were downloaded and verified
+	Available float64
+	// Score is a rating from 0 to 100
+	Score int
+}
+
+// SiteDetail is the full record of a site
+type SiteDetail struct {
+	Site
+	Zone      string
+	CreatedAt time.Time
+	// CheckingUntil is set while the site is reserved by a checker
+	CheckingUntil *time.Time
+	Uptime        float64
+	// Latency is nil unless the site was accessible during the last check
+	Latency     *time.Duration
+	ContentSize int
+	GatewayHits int64
+}
+
+type Suggestion struct {
+	Domain  string
+	Unicode string
+	Title   string
+}
+
+type CheckResult struct {
 	Status      SiteStatus
 	InStorage   bool
 	SpamContent bool
-	CheckedAt   time.Time
+	// Robots is nil if robots.txt could not be fetched, the stored policy is kept then
+	Robots      *RobotsPolicy
+	Lang        string
+	Categories  []string
+	Latency     time.Duration
+	ContentSize int
+	Title       string
+	Server      string
+	PoweredBy   string
+	Tech        []string
+	Redirect    RedirectKind
+	RedirectTo  string
+	Bag         *BagHealth
+	SiteId      []byte
+	Wallet      string
+	// fields filled by probes are only stored if the probe ran, the stored values are kept otherwise.
+	// HasMetadata covers Title, Lang and Categories, HasSpam covers SpamContent,
+	// HasFingerprint covers Server, PoweredBy and Tech
+	HasMetadata    bool
+	HasSpam        bool
+	HasFingerprint bool
 }
 
 type Cursor struct {
 	Value  any
 	Domain string
+	// Id is the key of lists ordered by a numeric id, e.g. snapshots. Value and Domain are unused then
+	Id int64
 }
 
 type SitesStore struct {
@@ -73,16 +201,22 @@ func NewSitesStore(db *pgxpool.Pool) *SitesStore {
 	}
 }
 
-func (r *SitesStore) GetStats(ctx context.Context) (*Stats, error) {
+// GetStats counts sites of the zone or of all zones if zone is empty. counts are as of the last RefreshStats
+func (r *SitesStore) GetStats(ctx context.Context, zone string) (*Stats, error) {
 	const sql = `
 	select 
-		count(*) as total,
-		count(*) filter (where status != $1) as has_sites,
-		count(*) filter (where status = $2) as active
-	from sites
+		coalesce(sum(domains), 0)::int as total,
+		coalesce(sum(sites), 0)::int as has_sites,
+		coalesce(sum(active), 0)::int as active
+	from site_stats
+	where $1 = '' or zone = $1
 	`
 	var total, sites, activeSites int
-	err := r.db.QueryRow(ctx, sql, StatusNoSite, StatusAccessible).Scan(&total, &sites, &activeSites)
+	err := r.db.QueryRow(ctx, sql, zone).Scan(&total, &sites, &activeSites)
+	if err != nil {
+		return nil, err
+	}
+	tech, err := r.countTech(ctx, zone)
 	if err != nil {
 		return nil, err
 	}
@@ -90,23 +224,84 @@ func (r *SitesStore) GetStats(ctx context.Context) (*Stats, error) {
 		TotalDomains: total,
 		TotalSites:   sites,
 		ActiveSites:  activeSites,
+		Tech:         tech,
 	}, nil
 }
 
-func (r *SitesStore) GetRandomSite(ctx context.Context) (*Site, error) {
+func (r *SitesStore) countTech(ctx context.Context, zone string) (map[string]int, error) {
 	const sql = `
-	select domain, unicode, address, status, in_storage, spam_content, checked_at from sites
-	where status = $1 and spam_content = false
-	order by random()
-	limit 1
+	select tech, sum(sites)::int from site_tech_stats
+	where $1 = '' or zone = $1
+	group by tech
 	`
-	var s Site
-	err := r.db.QueryRow(ctx, sql, StatusAccessible).Scan(
-		&s.Domain, &s.Unicode, &s.Address, &s.Status, &s.InStorage, &s.SpamContent, &s.CheckedAt)
+	rows, err := r.db.Query(ctx, sql, zone)
 	if err != nil {
 		return nil, err
 	}
-	return &s, nil
+	defer rows.Close()
+	res := make(map[string]int)
+	for rows.Next() {
+		var tech string
+		var count int
+		if err := rows.Scan(&tech, &count); err != nil {
+			return nil, err
+		}
+		res[tech] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CountZones returns statistics of each zone that has domains as of the last RefreshStats
+func (r *SitesStore) CountZones(ctx context.Context) (map[string]ZoneStats, error) {
+	const sql = `
+	select zone, domains, sites, active, storage, spam from site_stats
+	`
+	rows, err := r.db.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make(map[string]ZoneStats)
+	for rows.Next() {
+		var zone string
+		var z ZoneStats
+		if err := rows.Scan(&zone, &z.Domains, &z.Sites, &z.Active, &z.Storage, &z.Spam); err != nil {
+			return nil, err
+		}
+		res[zone] = z
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RefreshStats recounts statistics read by GetStats and CountZones, so reading them doesn't depend on the number of sites
+func (r *SitesStore) RefreshStats(ctx context.Context) error {
+	if _, err := r.db.Exec(ctx, "refresh materialized view concurrently site_stats"); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(ctx, "refresh materialized view concurrently site_tech_stats")
+	return err
+}
+
+// GetRandomSite returns a random accessible site. if weighted is set the chance of a site
+// to be picked is proportional to its rank (weighted reservoir sampling by Efraimidis-Spirakis)
+func (r *SitesStore) GetRandomSite(ctx context.Context, weighted bool) (*Site, error) {
+	const sql = `
+	select ` + siteColumns + ` from sites
+	where status = $1 and spam_content = false
+	order by %s
+	limit 1
+	`
+	order := "random()"
+	if weighted {
+		order = "-ln(1 - random()) / greatest(rank, 0.01)"
+	}
+	return scanSite(r.db.QueryRow(ctx, fmt.Sprintf(sql, order), StatusAccessible))
 }
 
 func (r *SitesStore) List(ctx context.Context, params *ListFilters, cursor *Cursor, limit int) ([]Site, *Cursor, error) {
@@ -122,11 +317,11 @@ func (r *SitesStore) List(ctx context.Context, params *ListFilters, cursor *Curs
 		if !rows.Next() {
 			break
 		}
-		var s Site
-		if err := rows.Scan(&s.Domain, &s.Unicode, &s.Address, &s.Status, &s.InStorage, &s.SpamContent, &s.CheckedAt); err != nil {
+		s, err := scanSite(rows)
+		if err != nil {
 			return nil, nil, err
 		}
-		sites = append(sites, s)
+		sites = append(sites, *s)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, nil, err
@@ -139,6 +334,12 @@ func (r *SitesStore) List(ctx context.Context, params *ListFilters, cursor *Curs
 		switch params.SortBy {
 		case SortByCheckedAt:
 			val = last.CheckedAt.Unix()
+		case SortByBacklinks:
+			val = last.Backlinks
+		case SortByRank:
+			val = last.Rank
+		case SortByChangedAt:
+			val = last.ContentChangedAt.Unix()
 		default:
 		}
 		nextCursor = &Cursor{
@@ -149,22 +350,46 @@ func (r *SitesStore) List(ctx context.Context, params *ListFilters, cursor *Curs
 	return sites, nextCursor, nil
 }
 
-func (r *SitesStore) ReserveCheck(ctx context.Context, stale time.Duration, hold time.Duration, limit int) ([]string, error) {
+// ReserveCheck locks up to limit stale sites for checking. zones take turns according to their weights,
+// so a zone with the weight 2 gets twice as many sites reserved as a zone with the weight 1.
+// sites of zones missing from the list, e.g. of a removed domain source, share a bucket with the weight 1
+func (r *SitesStore) ReserveCheck(ctx context.Context, stale time.Duration, hold time.Duration, limit int, zones []string, weights []float64) ([]string, error) {
 	const sql = `
+	with candidates as (
+		select s.domain, row_number() over (partition by z.zone order by s.checked_at asc) / z.weight as turn
+		from unnest($4::text[], $5::float8[]) as z(zone, weight)
+		cross join lateral (
+			select domain, checked_at from sites
+			where zone = z.zone
+				and checked_at + $1 < now()
+				and (checking_until is null or checking_until < now())
+			order by checked_at asc
+			limit $3
+			for update skip locked
+		) as s
+		union all
+		select s.domain, (row_number() over (order by s.checked_at asc))::float8 as turn
+		from (
+			select domain, checked_at from sites
+			where zone <> all($4::text[])
+				and checked_at + $1 < now()
+				and (checking_until is null or checking_until < now())
+			order by checked_at asc
+			limit $3
+			for update skip locked
+		) as s
+	)
 	update sites
 	set checking_until = now() + $2
 	from (
-		select domain from sites
-		where checked_at + $1 < now()
-			and (checking_until is null or checking_until < now())
-		order by checked_at asc
+		select domain from candidates
+		order by turn asc
 		limit $3
-		for update skip locked
 	) as stale
 	where sites.domain = stale.domain
 	returning sites.domain
 	`
-	rows, err := r.db.Query(ctx, sql, stale, hold, limit)
+	rows, err := r.db.Query(ctx, sql, stale, hold, limit, zones, weights)
 	if err != nil {
 		return nil, err
 	}
@@ -184,17 +409,205 @@ func (r *SitesStore) ReserveCheck(ctx context.Context, stale time.Duration, hold
 	return res, nil
 }
 
-func (r *SitesStore) FinalizeCheck(ctx context.Context, domain string, status SiteStatus, inStorage bool, spamContent bool) error {
+// Postpone releases the site reserved for checking, so it's reserved again after the delay
+func (r *SitesStore) Postpone(ctx context.Context, domain string, delay time.Duration) error {
+	const sql = `
+	update sites set checking_until = now() + $2
+	where domain = $1
+	`
+	_, err := r.db.Exec(ctx, sql, domain, delay)
+	return err
+}
+
+// FinalizeCheck stores the result of the check. the content of the main page is only updated by accessible checks,
+// so a site that is temporarily down keeps its title, language, categories and the like
+func (r *SitesStore) FinalizeCheck(ctx context.Context, domain string, res *CheckResult) error {
 	const sql = `
 	update sites set
 		status = $2,
 		in_storage = $3,
-		spam_content = $4,
+		spam_content = case when $2 = $12 and $27 then $4 else spam_content end,
+		robots = case when $2 = $12 then coalesce($5, robots) else robots end,
+		lang = case when $2 = $12 and $26 then $6 else lang end,
+		categories = case when $2 = $12 and $26 then $7 else categories end,
+		latency_ms = $8,
+		content_size = case when $2 = $12 then $9 else content_size end,
+		title = case when $2 = $12 and $26 then $13 else title end,
+		server = case when $2 = $12 and $28 then $14 else server end,
+		powered_by = case when $2 = $12 and $28 then $15 else powered_by end,
+		tech = case when $2 = $12 and $28 then $16 else tech end,
+		redirect = case when $2 = $12 then $17 else redirect end,
+		redirect_to = case when $2 = $12 then $18 else redirect_to end,
+		bag_peers = $19,
+		bag_size = case when $19::int is null then null else coalesce($20, bag_size) end,
+		bag_files = case when $19::int is null then null else coalesce($21, bag_files) end,
+		bag_available = $22,
+		bag_health = $23,
+		site_id = $24,
+		wallet = $25,
+		uptime = case
+			when $2 = $10 then 0
+			else uptime * (1 - $11::float8) + (case when $2 = $12 then $11::float8 else 0 end)
+		end,
 		checked_at = now(),
 		checking_until = null
 	where domain = $1
 	`
-	_, err := r.db.Exec(ctx, sql, domain, status, inStorage, spamContent)
+	categories := res.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	tech := res.Tech
+	if tech == nil {
+		tech = []string{}
+	}
+	var bagPeers, bagFiles, bagHealth *int
+	var bagSize *int64
+	var bagAvailable *float64
+	if res.Bag != nil {
+		bagPeers, bagAvailable, bagHealth = &res.Bag.Peers, &res.Bag.Available, &res.Bag.Score
+		// the size of a bag that could not be fetched is unknown, the last known one is kept
+		if res.Status == StatusAccessible {
+			bagSize, bagFiles = &res.Bag.Size, &res.Bag.Files
+		}
+	}
+	var latency *int64
+	if res.Status == StatusAccessible {
+		ms := res.Latency.Milliseconds()
+		latency = &ms
+	}
+	_, err := r.db.Exec(ctx, sql, domain, res.Status, res.InStorage, res.SpamContent, res.Robots, res.Lang, categories,
+		latency, res.ContentSize, StatusNoSite, uptimeWeight, StatusAccessible, res.Title,
+		res.Server, res.PoweredBy, tech, res.Redirect, res.RedirectTo,
+		bagPeers, bagSize, bagFiles, bagAvailable, bagHealth, res.SiteId, res.Wallet,
+		res.HasMetadata, res.HasSpam, res.HasFingerprint)
+	return err
+}
+
+func (r *SitesStore) Get(ctx context.Context, domain string) (*SiteDetail, error) {
+	const sql = `
+	select ` + siteColumns + `, zone, created_at, checking_until, uptime, latency_ms, content_size, gateway_hits from sites
+	where domain = $1
+	`
+	var d SiteDetail
+	var latencyMs *int64
+	s, err := scanSite(r.db.QueryRow(ctx, sql, domain), &d.Zone, &d.CreatedAt, &d.CheckingUntil, &d.Uptime, &latencyMs, &d.ContentSize, &d.GatewayHits)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.Site = *s
+	if latencyMs != nil {
+		latency := time.Duration(*latencyMs) * time.Millisecond
+		d.Latency = &latency
+	}
+	return &d, nil
+}
+
+// ListBySiteId returns sites which "TON Site" record points to the ADNL address or the bag
+func (r *SitesStore) ListBySiteId(ctx context.Context, id []byte, inStorage bool, after string, limit int) ([]Site, error) {
+	const sql = `
+	select ` + siteColumns + ` from sites
+	where site_id = $1 and in_storage = $2 and domain > $3
+	order by domain asc
+	limit $4
+	`
+	rows, err := r.db.Query(ctx, sql, id, inStorage, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Site, error) {
+		s, err := scanSite(row)
+		if err != nil {
+			return Site{}, err
+		}
+		return *s, nil
+	})
+}
+
+// ListByWallet returns domains which "wallet" DNS record points to the raw address
+func (r *SitesStore) ListByWallet(ctx context.Context, wallet string, after string, limit int) ([]Site, error) {
+	const sql = `
+	select ` + siteColumns + ` from sites
+	where wallet = $1 and domain > $2
+	order by domain asc
+	limit $3
+	`
+	rows, err := r.db.Query(ctx, sql, wallet, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Site, error) {
+		s, err := scanSite(row)
+		if err != nil {
+			return Site{}, err
+		}
+		return *s, nil
+	})
+}
+
+// Suggest returns the highest ranked accessible non-spam sites which domain, unicode form or title starts with the prefix
+func (r *SitesStore) Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error) {
+	// conditions must match the partial indexes for the planner to use them
+	sql := fmt.Sprintf(`
+	select domain, unicode, title from sites
+	where status = %d and spam_content = false
+		and (domain like $1 or lower(unicode) like $1 or lower(title) like $1)
+	order by rank desc, domain asc
+	limit $2
+	`, StatusAccessible)
+	rows, err := r.db.Query(ctx, sql, escapeLikeSearch(strings.ToLower(prefix))+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Suggestion, error) {
+		var s Suggestion
+		err := row.Scan(&s.Domain, &s.Unicode, &s.Title)
+		return s, err
+	})
+}
+
+// AddGatewayHits increments the number of gateway requests served for the domains
+func (r *SitesStore) AddGatewayHits(ctx context.Context, hits map[string]int) error {
+	const sql = `
+	update sites set
+		gateway_hits = gateway_hits + h.hits
+	from unnest($1::text[], $2::bigint[]) as h(domain, hits)
+	where sites.domain = h.domain
+	`
+	domains := make([]string, 0, len(hits))
+	counts := make([]int, 0, len(hits))
+	for domain, count := range hits {
+		domains = append(domains, domain)
+		counts = append(counts, count)
+	}
+	_, err := r.db.Exec(ctx, sql, domains, counts)
+	return err
+}
+
+// UpdateRanks recalculates relevance rank of every site. accessible sites get up to 40 points for uptime,
+// 20 for latency, 30 for backlinks, 20 for content size and 20 for gateway popularity, spam sites keep only
+// a tenth of their rank. unlike spam, phishing is not penalized as the checker doesn't detect it yet.
+// only rows which rank has changed are written
+func (r *SitesStore) UpdateRanks(ctx context.Context) error {
+	const sql = `
+	update sites set rank = ranked.rank
+	from (
+		select domain, case when status != $1 then 0 else (
+			uptime * 40
+			+ greatest(0, 20 - coalesce(latency_ms, 10000) / 500.0)
+			+ least(ln(1 + backlinks), 5) * 6
+			+ least(ln(1 + content_size / 1000.0), 4) * 5
+			+ least(ln(1 + gateway_hits), 10) * 2
+		) * (case when spam_content then 0.1 else 1 end)
+		end as rank
+		from sites
+	) as ranked
+	where sites.domain = ranked.domain and sites.rank is distinct from ranked.rank
+	`
+	_, err := r.db.Exec(ctx, sql, StatusAccessible)
 	return err
 }
 
@@ -231,9 +644,62 @@ func (r *SitesStore) AddDomains(ctx context.Context, sites ...SiteCreate) error
 	return err
 }
 
+// FilterUnknown returns those of the passed domains that are not indexed yet
+func (r *SitesStore) FilterUnknown(ctx context.Context, domains []string) ([]string, error) {
+	const sql = `
+	select d from unnest($1::text[]) as d
+	where not exists (
+		select 1 from sites
+		where domain = d
+	)
+	`
+	rows, err := r.db.Query(ctx, sql, domains)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]string, 0)
+	for rows.Next() {
+		var domain string
+		if err := rows.Scan(&domain); err != nil {
+			return nil, err
+		}
+		res = append(res, domain)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// scanSite scans siteColumns followed by the extra columns
+func scanSite(row pgx.Row, extra ...any) (*Site, error) {
+	var s Site
+	var bagPeers, bagFiles, bagHealth *int
+	var bagSize *int64
+	var bagAvailable *float64
+	dest := []any{&s.Domain, &s.Unicode, &s.Address, &s.Status, &s.InStorage, &s.SpamContent, &s.CheckedAt, &s.Backlinks, &s.Robots, &s.Lang, &s.Categories, &s.Rank, &s.Title, &s.Server, &s.PoweredBy, &s.Tech, &s.ClearnetDeps, &s.Redirect, &s.RedirectTo, &s.ContentChangedAt,
+		&bagPeers, &bagSize, &bagFiles, &bagAvailable, &bagHealth, &s.SiteId, &s.Wallet}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	if bagHealth != nil {
+		s.Bag = &BagHealth{
+			Peers:     *bagPeers,
+			Size:      *bagSize,
+			Files:     *bagFiles,
+			Available: *bagAvailable,
+			Score:     *bagHealth,
+		}
+	}
+	return &s, nil
+}
+
 func buildListQuery(params *ListFilters, cursor *Cursor, limit int) (string, []any) {
 	const baseSql = `
-	select domain, unicode, address, status, in_storage, spam_content, checked_at from sites
+	select ` + siteColumns + ` from sites
 	%s
 	order by %s
 	limit $%d
@@ -241,9 +707,13 @@ func buildListQuery(params *ListFilters, cursor *Cursor, limit int) (string, []a
 	wheres := make([]string, 0)
 	args := make([]any, 0)
 
-	if params.Search != "" {
-		wheres = append(wheres, fmt.Sprintf("domain ilike $%d", len(args)+1))
-		args = append(args, "%"+escapeLikeSearch(params.Search)+"%")
+	for _, term := range params.Terms {
+		wheres = append(wheres, fmt.Sprintf("(domain ilike $%d or unicode ilike $%d or title ilike $%d)", len(args)+1, len(args)+1, len(args)+1))
+		args = append(args, "%"+escapeLikeSearch(term)+"%")
+	}
+	for _, term := range params.ExcludeTerms {
+		wheres = append(wheres, fmt.Sprintf("not (domain ilike $%d or unicode ilike $%d or title ilike $%d)", len(args)+1, len(args)+1, len(args)+1))
+		args = append(args, "%"+escapeLikeSearch(term)+"%")
 	}
 
 	if params.Inaccessible {
@@ -265,6 +735,33 @@ func buildListQuery(params *ListFilters, cursor *Cursor, limit int) (string, []a
 		wheres = append(wheres, fmt.Sprintf("zone = $%d", len(args)+1))
 		args = append(args, params.Zone)
 	}
+	if params.Lang != "" {
+		wheres = append(wheres, fmt.Sprintf("lang = $%d", len(args)+1))
+		args = append(args, params.Lang)
+	}
+	if params.InStorage != nil {
+		wheres = append(wheres, fmt.Sprintf("in_storage = $%d", len(args)+1))
+		args = append(args, *params.InStorage)
+	}
+	if params.Category != "" {
+		wheres = append(wheres, fmt.Sprintf("categories @> array[$%d::text]", len(args)+1))
+		args = append(args, params.Category)
+	}
+	if params.SelfContained != nil {
+		match := ">"
+		if *params.SelfContained {
+			match = "="
+		}
+		wheres = append(wheres, fmt.Sprintf("clearnet_deps %s 0", match))
+	}
+	if params.Redirect != nil {
+		wheres = append(wheres, fmt.Sprintf("redirect = $%d", len(args)+1))
+		args = append(args, *params.Redirect)
+	}
+	if params.Tech != "" {
+		wheres = append(wheres, fmt.Sprintf("tech @> array[$%d::text]", len(args)+1))
+		args = append(args, params.Tech)
+	}
 
 	if cursor != nil {
 		if params.SortBy == SortByDomain {
@@ -280,7 +777,7 @@ func buildListQuery(params *ListFilters, cursor *Cursor, limit int) (string, []a
 				comp = "<"
 			}
 			wheres = append(wheres, fmt.Sprintf(
-				"%s %s $%d or (%s = $%d and domain > $%d)",
+				"(%s %s $%d or (%s = $%d and domain > $%d))",
 				params.SortBy, comp, len(args)+1, params.SortBy, len(args)+1, len(args)+2,
 			))
 			args = append(args, cursor.Value, cursor.Domain)
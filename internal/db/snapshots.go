@@ -0,0 +1,164 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SnapshotRetention bounds the archive of each site, zero values disable the corresponding limit.
+// the latest snapshot is always kept
+type SnapshotRetention struct {
+	MaxAge   time.Duration
+	MaxCount int
+}
+
+type Snapshot struct {
+	Id        int64
+	Hash      []byte
+	Size      int
+	Content   []byte
+	CreatedAt time.Time
+}
+
+type SnapshotsStore struct {
+	db *pgxpool.Pool
+}
+
+func NewSnapshotsStore(db *pgxpool.Pool) *SnapshotsStore {
+	return &SnapshotsStore{
+		db: db,
+	}
+}
+
+// Save archives the main page of the site if it differs from the latest snapshot, bumps content_changed_at
+// and prunes snapshots exceeding the retention. reports whether the content has changed, the first snapshot
+// of a site is not a change, so content_changed_at stays as is then
+func (r *SnapshotsStore) Save(ctx context.Context, domain string, content []byte, retention SnapshotRetention) (bool, error) {
+	const latestSql = `
+	select hash from snapshots
+	where domain = $1
+	order by id desc
+	limit 1
+	`
+	const insertSql = `
+	insert into snapshots (domain, hash, size, content)
+	values ($1, $2, $3, $4)
+	`
+	const updateSql = `
+	update sites set content_changed_at = now()
+	where domain = $1
+	`
+	const pruneSql = `
+	delete from snapshots s
+	using (
+		select id, created_at, row_number() over (order by id desc) as n
+		from snapshots
+		where domain = $1
+	) r
+	where s.id = r.id and r.n > 1 and (
+		($2::int > 0 and r.n > $2::int) or
+		($3::float8 > 0 and r.created_at < now() - make_interval(secs => $3::float8))
+	)
+	`
+	sum := sha256.Sum256(content)
+	compressed, err := compress(content)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	var latest []byte
+	err = tx.QueryRow(ctx, latestSql, domain).Scan(&latest)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+	if bytes.Equal(latest, sum[:]) {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, insertSql, domain, sum[:], len(content), compressed); err != nil {
+		return false, err
+	}
+	changed := latest != nil
+	if changed {
+		if _, err := tx.Exec(ctx, updateSql, domain); err != nil {
+			return false, err
+		}
+	}
+	if _, err := tx.Exec(ctx, pruneSql, domain, retention.MaxCount, retention.MaxAge.Seconds()); err != nil {
+		return false, err
+	}
+	return changed, tx.Commit(ctx)
+}
+
+// List returns snapshots of the site without their content, newest first. before is an exclusive id bound, zero for none
+func (r *SnapshotsStore) List(ctx context.Context, domain string, before int64, limit int) ([]Snapshot, error) {
+	const sql = `
+	select id, hash, size, created_at from snapshots
+	where domain = $1 and ($2 = 0 or id < $2)
+	order by id desc
+	limit $3
+	`
+	rows, err := r.db.Query(ctx, sql, domain, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Snapshot, error) {
+		var s Snapshot
+		err := row.Scan(&s.Id, &s.Hash, &s.Size, &s.CreatedAt)
+		return s, err
+	})
+}
+
+func (r *SnapshotsStore) Get(ctx context.Context, domain string, id int64) (*Snapshot, error) {
+	const sql = `
+	select id, hash, size, content, created_at from snapshots
+	where domain = $1 and id = $2
+	`
+	var s Snapshot
+	var compressed []byte
+	err := r.db.QueryRow(ctx, sql, domain, id).Scan(&s.Id, &s.Hash, &s.Size, &compressed, &s.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Content, err = decompress(compressed)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+// Recorder periodically refreshes site statistics and saves their snapshots to chart the ecosystem growth
+type Recorder struct {
+	sites    *db.SitesStore
+	history  *db.HistoryStore
+	refresh  time.Duration
+	interval time.Duration
+	closer   context.CancelFunc
+}
+
+// NewRecorder creates a recorder that refreshes statistics every refresh and saves a snapshot every interval
+func NewRecorder(sites *db.SitesStore, history *db.HistoryStore, refresh time.Duration, interval time.Duration) *Recorder {
+	return &Recorder{
+		sites:    sites,
+		history:  history,
+		refresh:  refresh,
+		interval: interval,
+	}
+}
+
+func (r *Recorder) Start(ctx context.Context) {
+	ctx, r.closer = context.WithCancel(ctx)
+	go r.worker(ctx)
+}
+
+func (r *Recorder) Close() {
+	if r.closer != nil {
+		r.closer()
+	}
+}
+
+func (r *Recorder) worker(ctx context.Context) {
+	ticker := time.NewTicker(r.refresh)
+	defer ticker.Stop()
+	var recordedAt time.Time
+	for {
+		if err := r.sites.RefreshStats(ctx); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				log.Printf("[HISTORY] unable to refresh stats: %v", err)
+			}
+		} else if time.Since(recordedAt) >= r.interval {
+			if err := r.history.Record(ctx); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Printf("[HISTORY] unable to record stats: %v", err)
+				}
+			} else {
+				recordedAt = time.Now()
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ranker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/oxylume/index/internal/db"
+)
+
+// Ranker periodically recalculates relevance rank of sites
+// and collects gateway popularity that takes part in it
+type Ranker struct {
+	sites    *db.SitesStore
+	interval time.Duration
+	hits     map[string]int
+	mx       sync.Mutex
+	closer   context.CancelFunc
+}
+
+func NewRanker(sites *db.SitesStore, interval time.Duration) *Ranker {
+	return &Ranker{
+		sites:    sites,
+		interval: interval,
+		hits:     make(map[string]int),
+	}
+}
+
+func (r *Ranker) Start(ctx context.Context) {
+	ctx, r.closer = context.WithCancel(ctx)
+	go r.worker(ctx)
+}
+
+func (r *Ranker) Close() {
+	if r.closer != nil {
+		r.closer()
+	}
+}
+
+// Hit registers a gateway request to the domain
+func (r *Ranker) Hit(domain string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.hits[domain]++
+}
+
+func (r *Ranker) worker(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if err := r.update(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("[RANKER] unable to update ranks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Ranker) update(ctx context.Context) error {
+	r.mx.Lock()
+	hits := r.hits
+	r.hits = make(map[string]int)
+	r.mx.Unlock()
+	if len(hits) > 0 {
+		if err := r.sites.AddGatewayHits(ctx, hits); err != nil {
+			return err
+		}
+	}
+	return r.sites.UpdateRanks(ctx)
+}
@@ -0,0 +1,3 @@
+drop table links;
+drop index idx_sites_sort_backlinks;
+alter table sites drop column backlinks;
@@ -0,0 +1,11 @@
+alter table sites add column backlinks int not null default 0;
+
+create index idx_sites_sort_backlinks on sites(backlinks, domain);
+
+create table links (
+    source text not null references sites(domain) on delete cascade,
+    target text not null,
+    primary key (source, target)
+);
+
+create index idx_links_target on links(target, source);
@@ -0,0 +1,9 @@
+create table pages (
+    domain text not null references sites(domain) on delete cascade,
+    path text not null,
+    status int not null,
+    title text not null default '',
+    content text not null default '',
+    fetched_at timestamptz not null default now(),
+    primary key (domain, path)
+);
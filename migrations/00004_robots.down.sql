@@ -0,0 +1 @@
+alter table sites drop column robots;
@@ -0,0 +1 @@
+alter table sites add column robots int not null default 0;
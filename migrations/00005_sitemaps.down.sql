@@ -0,0 +1 @@
+drop table sitemap_urls;
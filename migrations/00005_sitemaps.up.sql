@@ -0,0 +1,7 @@
+create table sitemap_urls (
+    domain text not null references sites(domain) on delete cascade,
+    path text not null,
+    lastmod timestamptz default null,
+    changed_at timestamptz not null default now(),
+    primary key (domain, path)
+);
@@ -0,0 +1,4 @@
+drop index idx_sites_categories;
+drop index idx_sites_lang;
+alter table sites drop column categories;
+alter table sites drop column lang;
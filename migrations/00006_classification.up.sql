@@ -0,0 +1,5 @@
+alter table sites add column lang text not null default '';
+alter table sites add column categories text[] not null default '{}';
+
+create index idx_sites_lang on sites(lang);
+create index idx_sites_categories on sites using gin(categories);
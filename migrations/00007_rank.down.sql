@@ -0,0 +1,6 @@
+drop index idx_sites_sort_rank;
+alter table sites drop column rank;
+alter table sites drop column gateway_hits;
+alter table sites drop column content_size;
+alter table sites drop column latency_ms;
+alter table sites drop column uptime;
@@ -0,0 +1,7 @@
+alter table sites add column uptime double precision not null default 0;
+alter table sites add column latency_ms int default null;
+alter table sites add column content_size int not null default 0;
+alter table sites add column gateway_hits bigint not null default 0;
+alter table sites add column rank double precision not null default 0;
+
+create index idx_sites_sort_rank on sites(rank, domain);
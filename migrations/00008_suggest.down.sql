@@ -0,0 +1,4 @@
+drop index idx_sites_suggest_title;
+drop index idx_sites_suggest_unicode;
+drop index idx_sites_suggest_domain;
+alter table sites drop column title;
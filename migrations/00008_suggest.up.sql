@@ -0,0 +1,8 @@
+alter table sites add column title text not null default '';
+
+create index idx_sites_suggest_domain on sites(domain text_pattern_ops)
+    where status = 2 and spam_content = false;
+create index idx_sites_suggest_unicode on sites(lower(unicode) text_pattern_ops)
+    where status = 2 and spam_content = false;
+create index idx_sites_suggest_title on sites(lower(title) text_pattern_ops)
+    where status = 2 and spam_content = false;
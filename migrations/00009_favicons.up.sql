@@ -0,0 +1,7 @@
+create table favicons (
+    domain text primary key references sites(domain) on delete cascade,
+    content bytea default null,
+    content_type text not null default '',
+    hash bytea default null,
+    checked_at timestamptz not null default now()
+);
@@ -0,0 +1,4 @@
+drop index idx_sites_tech;
+alter table sites drop column tech;
+alter table sites drop column powered_by;
+alter table sites drop column server;
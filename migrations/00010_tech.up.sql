@@ -0,0 +1,5 @@
+alter table sites add column server text not null default '';
+alter table sites add column powered_by text not null default '';
+alter table sites add column tech text[] not null default '{}';
+
+create index idx_sites_tech on sites using gin(tech);
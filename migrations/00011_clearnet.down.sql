@@ -0,0 +1,2 @@
+drop table clearnet_deps;
+alter table sites drop column clearnet_deps;
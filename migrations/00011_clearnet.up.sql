@@ -0,0 +1,9 @@
+alter table sites add column clearnet_deps int not null default 0;
+
+create table clearnet_deps (
+    domain text not null references sites(domain) on delete cascade,
+    url text not null,
+    host text not null,
+    kind text not null,
+    primary key (domain, url)
+);
@@ -0,0 +1 @@
+drop table audit_issues;
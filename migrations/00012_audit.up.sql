@@ -0,0 +1,8 @@
+create table audit_issues (
+    domain text not null references sites(domain) on delete cascade,
+    target text not null,
+    source text not null,
+    kind int not null,
+    status int not null,
+    primary key (domain, target)
+);
@@ -0,0 +1,2 @@
+alter table sites drop column redirect_to;
+alter table sites drop column redirect;
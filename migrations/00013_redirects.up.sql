@@ -0,0 +1,2 @@
+alter table sites add column redirect int not null default 0;
+alter table sites add column redirect_to text not null default '';
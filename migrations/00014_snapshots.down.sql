@@ -0,0 +1,3 @@
+drop table snapshots;
+drop index idx_sites_sort_content_changed_at;
+alter table sites drop column content_changed_at;
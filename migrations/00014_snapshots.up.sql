@@ -0,0 +1,14 @@
+alter table sites add column content_changed_at timestamptz not null default 'epoch';
+
+create index idx_sites_sort_content_changed_at on sites(content_changed_at, domain);
+
+create table snapshots (
+    id bigint generated always as identity primary key,
+    domain text not null references sites(domain) on delete cascade,
+    hash bytea not null,
+    size int not null,
+    content bytea not null,
+    created_at timestamptz not null default now()
+);
+
+create index idx_snapshots_domain on snapshots(domain, id);
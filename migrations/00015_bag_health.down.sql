@@ -0,0 +1,5 @@
+alter table sites drop column bag_health;
+alter table sites drop column bag_available;
+alter table sites drop column bag_files;
+alter table sites drop column bag_size;
+alter table sites drop column bag_peers;
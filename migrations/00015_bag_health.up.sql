@@ -0,0 +1,5 @@
+alter table sites add column bag_peers int default null;
+alter table sites add column bag_size bigint default null;
+alter table sites add column bag_files int default null;
+alter table sites add column bag_available double precision default null;
+alter table sites add column bag_health int default null;
@@ -0,0 +1 @@
+drop table site_hosts;
@@ -0,0 +1,11 @@
+create table site_hosts (
+    domain text not null references sites(domain) on delete cascade,
+    ip inet not null,
+    port int not null,
+    country text not null default '',
+    asn int not null default 0,
+    org text not null default '',
+    primary key (domain, ip, port)
+);
+
+create index idx_site_hosts_ip on site_hosts(ip);
@@ -0,0 +1,2 @@
+drop index if exists idx_sites_site_id;
+alter table sites drop column site_id;
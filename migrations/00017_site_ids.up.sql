@@ -0,0 +1,3 @@
+alter table sites add column site_id bytea default null;
+
+create index idx_sites_site_id on sites(site_id, in_storage, domain);
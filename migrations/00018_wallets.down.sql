@@ -0,0 +1,2 @@
+drop index if exists idx_sites_wallet;
+alter table sites drop column wallet;
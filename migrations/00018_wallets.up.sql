@@ -0,0 +1,3 @@
+alter table sites add column wallet text not null default '';
+
+create index idx_sites_wallet on sites(wallet, domain) where wallet <> '';
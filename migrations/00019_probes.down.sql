@@ -0,0 +1 @@
+drop table if exists probe_results;
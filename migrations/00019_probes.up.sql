@@ -0,0 +1,9 @@
+create table probe_results (
+    domain text not null references sites(domain) on delete cascade,
+    probe text not null,
+    findings jsonb not null default '[]',
+    error text not null default '',
+    duration_ms int not null default 0,
+    checked_at timestamptz not null default now(),
+    primary key (domain, probe)
+);
@@ -0,0 +1 @@
+drop index if exists idx_sites_zone_checked_at;
@@ -0,0 +1 @@
+create index idx_sites_zone_checked_at on sites(zone, checked_at);
@@ -0,0 +1 @@
+drop table if exists stats_history;
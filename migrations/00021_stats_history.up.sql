@@ -0,0 +1,12 @@
+create table stats_history (
+    zone text not null,
+    recorded_at timestamptz not null,
+    domains int not null,
+    sites int not null,
+    active int not null,
+    storage int not null,
+    spam int not null,
+    primary key (zone, recorded_at)
+);
+
+create index idx_stats_history_recorded_at on stats_history(recorded_at);
@@ -0,0 +1,2 @@
+drop materialized view if exists site_tech_stats;
+drop materialized view if exists site_stats;
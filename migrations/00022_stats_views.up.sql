@@ -0,0 +1,21 @@
+-- statuses are db.StatusNoSite = 0 and db.StatusAccessible = 2
+create materialized view site_stats as
+select
+    zone,
+    count(*)::int as domains,
+    (count(*) filter (where status != 0))::int as sites,
+    (count(*) filter (where status = 2))::int as active,
+    (count(*) filter (where status != 0 and in_storage))::int as storage,
+    (count(*) filter (where status != 0 and spam_content))::int as spam
+from sites
+group by zone;
+
+create unique index idx_site_stats_zone on site_stats(zone);
+
+create materialized view site_tech_stats as
+select zone, t as tech, count(*)::int as sites
+from sites, unnest(tech) as t
+where status = 2
+group by zone, t;
+
+create unique index idx_site_tech_stats_zone_tech on site_tech_stats(zone, tech);
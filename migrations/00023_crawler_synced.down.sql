@@ -0,0 +1 @@
+alter table crawler_state drop column synced_at;
@@ -0,0 +1 @@
+alter table crawler_state add column synced_at timestamptz;
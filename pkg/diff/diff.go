@@ -0,0 +1,199 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// maxEdits and maxLines bound the work of the diff, inputs that differ more or have more changed
+// lines are reported as fully replaced
+const (
+	maxEdits = 1000
+	maxLines = 20000
+)
+
+// Lines splits text into lines without the trailing newline characters
+func Lines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Diff returns the shortest edit script transforming a into b using the Myers algorithm
+func Diff(a, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, Edit{Op: Equal, Text: line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Op: Equal, Text: line})
+	}
+	return edits
+}
+
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n+m > maxLines {
+		return replace(a, b)
+	}
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// only the diagonals reachable at d are kept, so the trace grows quadratically with the edits
+	// rather than with the edits times the input size
+	trace := make([][]int, 0)
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return replace(a, b)
+}
+
+func replace(a, b []string) []Edit {
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a {
+		edits = append(edits, Edit{Op: Delete, Text: line})
+	}
+	for _, line := range b {
+		edits = append(edits, Edit{Op: Insert, Text: line})
+	}
+	return edits
+}
+
+// backtrack walks the trace back from the end, trace[d] holds the diagonals -d-1 to d+1 before step d
+func backtrack(a, b []string, trace [][]int) []Edit {
+	edits := make([]Edit, 0, len(a)+len(b))
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		offset := d + 1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, Edit{Op: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, Edit{Op: Insert, Text: b[y-1]})
+			} else {
+				edits = append(edits, Edit{Op: Delete, Text: a[x-1]})
+			}
+			x, y = prevX, prevY
+		}
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// Unified formats the edit script as a unified diff with the given number of context lines
+func Unified(from, to string, edits []Edit, context int) string {
+	var sb strings.Builder
+	// line numbers of a and b before each edit
+	aLines := make([]int, len(edits)+1)
+	bLines := make([]int, len(edits)+1)
+	for i, edit := range edits {
+		aLines[i+1], bLines[i+1] = aLines[i], bLines[i]
+		if edit.Op != Insert {
+			aLines[i+1]++
+		}
+		if edit.Op != Delete {
+			bLines[i+1]++
+		}
+	}
+	for i := 0; i < len(edits); i++ {
+		if edits[i].Op == Equal {
+			continue
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", from, to)
+		}
+		start := max(0, i-context)
+		end := i
+		// extend the hunk while the next change is close enough to share the context
+		for j := i; j < len(edits); j++ {
+			if edits[j].Op != Equal {
+				end = j
+				continue
+			}
+			if j-end > 2*context {
+				break
+			}
+		}
+		end = min(len(edits), end+context+1)
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", hunkStart(aLines[start], aLines[end]), aLines[end]-aLines[start],
+			hunkStart(bLines[start], bLines[end]), bLines[end]-bLines[start])
+		for _, edit := range edits[start:end] {
+			switch edit.Op {
+			case Equal:
+				sb.WriteByte(' ')
+			case Insert:
+				sb.WriteByte('+')
+			case Delete:
+				sb.WriteByte('-')
+			}
+			sb.WriteString(edit.Text)
+			sb.WriteByte('\n')
+		}
+		i = end - 1
+	}
+	return sb.String()
+}
+
+// hunkStart returns 1-based number of the first line of a hunk, empty ranges point to the line before them
+func hunkStart(start, end int) int {
+	if start == end {
+		return start
+	}
+	return start + 1
+}
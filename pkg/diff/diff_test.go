@@ -0,0 +1,141 @@
+package diff
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Edit
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\nc",
+			b:    "a\nb\nc",
+			want: []Edit{{Equal, "a"}, {Equal, "b"}, {Equal, "c"}},
+		},
+		{
+			name: "empty",
+			a:    "",
+			b:    "",
+			want: []Edit{},
+		},
+		{
+			name: "insert only",
+			a:    "a\nc",
+			b:    "a\nb\nc\nd",
+			want: []Edit{{Equal, "a"}, {Insert, "b"}, {Equal, "c"}, {Insert, "d"}},
+		},
+		{
+			name: "insert into empty",
+			a:    "",
+			b:    "a\nb",
+			want: []Edit{{Insert, "a"}, {Insert, "b"}},
+		},
+		{
+			name: "delete only",
+			a:    "a\nb\nc\nd",
+			b:    "b\nd",
+			want: []Edit{{Delete, "a"}, {Equal, "b"}, {Delete, "c"}, {Equal, "d"}},
+		},
+		{
+			name: "mixed",
+			a:    "a\nb\nc\nd\ne",
+			b:    "a\nx\nc\ne\nf",
+			want: []Edit{{Equal, "a"}, {Delete, "b"}, {Insert, "x"}, {Equal, "c"}, {Delete, "d"}, {Equal, "e"}, {Insert, "f"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(Lines(tt.a), Lines(tt.b))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffMinimal(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+	edits := Diff(a, b)
+	checkEdits(t, a, b, edits)
+	changes := 0
+	for _, edit := range edits {
+		if edit.Op != Equal {
+			changes++
+		}
+	}
+	if changes != 5 {
+		t.Errorf("Diff() made %d changes, want 5", changes)
+	}
+}
+
+func TestDiffOverMaxEdits(t *testing.T) {
+	a := make([]string, maxEdits)
+	b := make([]string, maxEdits)
+	for i := range a {
+		a[i] = "a" + strconv.Itoa(i)
+		b[i] = "b" + strconv.Itoa(i)
+	}
+	edits := Diff(a, b)
+	checkEdits(t, a, b, edits)
+	for i, edit := range edits {
+		want := Delete
+		if i >= len(a) {
+			want = Insert
+		}
+		if edit.Op != want {
+			t.Fatalf("edit %d is %v, want the input fully replaced", i, edit.Op)
+		}
+	}
+}
+
+func TestDiffOverMaxLines(t *testing.T) {
+	a := make([]string, maxLines)
+	for i := range a {
+		a[i] = strconv.Itoa(i)
+	}
+	b := append(append([]string{"first"}, a...), "last")
+	edits := Diff(a, b)
+	checkEdits(t, a, b, edits)
+	// only insertions are needed, deletions mean the changed lines are reported as replaced
+	if edits[0].Op != Delete {
+		t.Errorf("first edit is %v, want the changed lines fully replaced", edits[0].Op)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	edits := Diff(Lines("a\nb\nc\nd\ne\nf\ng\nh\n"), Lines("a\nb\nx\nd\ne\nf\ng\nh\ni\n"))
+	want := "--- 1\n+++ 2\n" +
+		"@@ -2,3 +2,3 @@\n b\n-c\n+x\n d\n" +
+		"@@ -8,1 +8,2 @@\n h\n+i\n"
+	if got := Unified("1", "2", edits, 1); got != want {
+		t.Errorf("Unified() = %q, want %q", got, want)
+	}
+	if got := Unified("1", "2", Diff(Lines("a"), Lines("a")), 1); got != "" {
+		t.Errorf("Unified() of equal input = %q, want empty", got)
+	}
+}
+
+// checkEdits verifies the edits transform a into b
+func checkEdits(t *testing.T, a, b []string, edits []Edit) {
+	t.Helper()
+	var gotA, gotB []string
+	for _, edit := range edits {
+		if edit.Op != Insert {
+			gotA = append(gotA, edit.Text)
+		}
+		if edit.Op != Delete {
+			gotB = append(gotB, edit.Text)
+		}
+	}
+	if !reflect.DeepEqual(gotA, a) || !reflect.DeepEqual(gotB, b) {
+		t.Fatal("edits do not transform a into b")
+	}
+}
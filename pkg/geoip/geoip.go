@@ -0,0 +1,117 @@
+package geoip
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Info struct {
+	Country string
+	Asn     int
+	Org     string
+}
+
+type ipRange struct {
+	start netip.Addr
+	end   netip.Addr
+	info  *Info
+}
+
+// DB maps ip addresses to their country and autonomous system. it's loaded from the ip2asn tsv
+// format (https://iptoasn.com): range_start, range_end, as_number, country_code, as_description
+type DB struct {
+	ranges []ipRange
+}
+
+// Load reads the database from the file, gzipped files are detected by the .gz extension
+func Load(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open geoip database: %w", err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read geoip database: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return parse(r)
+}
+
+func parse(r io.Reader) (*DB, error) {
+	db := &DB{}
+	// descriptions repeat a lot, share them between ranges of the same as
+	infos := make(map[Info]*Info)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 5 {
+			continue
+		}
+		start, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid range start at line %d: %w", line, err)
+		}
+		end, err := netip.ParseAddr(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid range end at line %d: %w", line, err)
+		}
+		asn, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid as number at line %d: %w", line, err)
+		}
+		// ranges that are not routed have no as
+		if asn == 0 {
+			continue
+		}
+		key := Info{Country: fields[3], Asn: asn, Org: fields[4]}
+		if key.Country == "None" {
+			key.Country = ""
+		}
+		info, ok := infos[key]
+		if !ok {
+			info = &key
+			infos[key] = info
+		}
+		db.ranges = append(db.ranges, ipRange{start: start, end: end, info: info})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read geoip database: %w", err)
+	}
+	slices.SortFunc(db.ranges, func(a, b ipRange) int {
+		return a.start.Compare(b.start)
+	})
+	return db, nil
+}
+
+// Lookup returns information about the address or nil if it's not in the database
+func (db *DB) Lookup(ip netip.Addr) *Info {
+	ip = ip.Unmap()
+	i, found := slices.BinarySearchFunc(db.ranges, ip, func(r ipRange, ip netip.Addr) int {
+		return r.start.Compare(ip)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 {
+		return nil
+	}
+	r := db.ranges[i]
+	if r.start.Is4() != ip.Is4() || r.end.Compare(ip) < 0 {
+		return nil
+	}
+	return r.info
+}
@@ -138,10 +138,59 @@ type Bag struct {
 	lastUsed   atomic.Int64
 }
 
+type BagStats struct {
+	Peers  int
+	Size   uint64
+	Files  uint32
+	Pieces uint32
+}
+
+// Stats returns the size of the bag and the number of peers it's currently connected to
+func (b *Bag) Stats() BagStats {
+	return BagStats{
+		Peers:  len(b.torrent.GetPeers()),
+		Size:   b.torrent.Header.TotalDataSize,
+		Files:  b.torrent.Header.FilesCount,
+		Pieces: b.torrent.Info.PiecesNum(),
+	}
+}
+
+// ProbePieces downloads the pieces from peers and returns how many of them were received and verified
+func (b *Bag) ProbePieces(ctx context.Context, pieces []uint32) int {
+	mask := make([]byte, b.torrent.Info.PiecesNum())
+	for _, piece := range pieces {
+		mask[piece] = 1
+	}
+	b.lastUsed.Store(time.Now().Unix())
+	fetcher := storage.NewPreFetcher(ctx, b.torrent, nil, uint32(len(pieces)), mask)
+	defer fetcher.Stop()
+	// pieces are waited for concurrently so a missing one does not hide the ones after it
+	var available atomic.Int32
+	var wg sync.WaitGroup
+	for _, piece := range pieces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := fetcher.WaitGet(ctx, piece); err != nil {
+				return
+			}
+			fetcher.Free(piece)
+			available.Add(1)
+		}()
+	}
+	wg.Wait()
+	b.lastUsed.Store(time.Now().Unix())
+	return int(available.Load())
+}
+
 func (b *Bag) GetFileOffsets(name string) (*storage.FileInfo, error) {
 	return b.torrent.GetFileOffsets(name)
 }
 
+func (b *Bag) ListFiles() ([]string, error) {
+	return b.torrent.ListFiles()
+}
+
 func (b *Bag) WriteFileTo(ctx context.Context, w io.Writer, file *storage.FileInfo, from uint64, to uint64, workers int) error {
 	pieceSize := uint64(b.torrent.Info.PieceSize)
 	fromOffset := from + uint64(file.FromPieceOffset)
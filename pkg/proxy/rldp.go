@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/ed25519"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
+	"net/netip"
 	"reflect"
 	"sync"
 
@@ -17,6 +19,9 @@ import (
 const maxChunkSize = 128 << 10
 const maxAnswerSize = 16 << 10
 
+// ErrDHTLookup wraps errors of looking up the address of an ADNL server in DHT
+var ErrDHTLookup = errors.New("dht lookup failed")
+
 type rldpReader struct {
 	client  *rldp.RLDP
 	queryId []byte
@@ -104,17 +109,24 @@ func NewRLDPConnector(gate *adnl.Gateway, dht *dht.Client) *RLDPConnector {
 func (c *RLDPConnector) GetConnection(ctx context.Context, id []byte) (*RLDPConnection, error) {
 	addresses, pubKey, err := c.dht.FindAddresses(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("unable to find address of %x in DHT: %w", id, err)
+		return nil, fmt.Errorf("unable to find address of %x: %w: %w", id, ErrDHTLookup, err)
 	}
 	if len(addresses.Addresses) == 0 {
 		return nil, fmt.Errorf("no addresses found for %x", id)
 	}
 	clientId := string(pubKey)
+	advertised := make([]netip.AddrPort, 0, len(addresses.Addresses))
+	for _, udp := range addresses.Addresses {
+		if ip, ok := netip.AddrFromSlice(udp.IP); ok {
+			advertised = append(advertised, netip.AddrPortFrom(ip.Unmap(), uint16(udp.Port)))
+		}
+	}
 
 	c.mx.RLock()
 	conn := c.conns[clientId]
 	c.mx.RUnlock()
 	if conn != nil {
+		conn.setAddresses(advertised)
 		return conn, nil
 	}
 
@@ -122,6 +134,7 @@ func (c *RLDPConnector) GetConnection(ctx context.Context, id []byte) (*RLDPConn
 	defer c.mx.Unlock()
 	conn = c.conns[clientId]
 	if conn != nil {
+		conn.setAddresses(advertised)
 		return conn, nil
 	}
 
@@ -133,7 +146,8 @@ func (c *RLDPConnector) GetConnection(ctx context.Context, id []byte) (*RLDPConn
 		}
 		client := rldp.NewClientV2(peer)
 		conn := &RLDPConnection{
-			client: client,
+			client:    client,
+			addresses: advertised,
 		}
 		client.SetOnQuery(conn.handleQuery)
 		peer.SetDisconnectHandler(c.removeClient)
@@ -153,9 +167,23 @@ func (c *RLDPConnector) removeClient(addr string, pubKey ed25519.PublicKey) {
 }
 
 type RLDPConnection struct {
-	client   *rldp.RLDP
-	requests map[string]io.Reader
-	mx       sync.RWMutex
+	client    *rldp.RLDP
+	requests  map[string]io.Reader
+	addresses []netip.AddrPort
+	mx        sync.RWMutex
+}
+
+// Addresses returns udp addresses the site advertised in DHT during the last lookup
+func (c *RLDPConnection) Addresses() []netip.AddrPort {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	return c.addresses
+}
+
+func (c *RLDPConnection) setAddresses(addresses []netip.AddrPort) {
+	c.mx.Lock()
+	c.addresses = addresses
+	c.mx.Unlock()
 }
 
 func (c *RLDPConnection) SendRequest(ctx context.Context, req *Request, payload io.Reader) (*Response, io.Reader, error) {
@@ -0,0 +1,71 @@
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+type item[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is a size bounded in-memory cache with expiring entries. when full, expired entries
+// are evicted first and if there are none an arbitrary entry is dropped
+type Cache[K comparable, V any] struct {
+	ttl      time.Duration
+	maxItems int
+	items    map[K]item[V]
+	mx       sync.Mutex
+}
+
+func New[K comparable, V any](ttl time.Duration, maxItems int) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:      ttl,
+		maxItems: maxItems,
+		items:    make(map[K]item[V]),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	it, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if time.Now().After(it.expiresAt) {
+		delete(c.items, key)
+		var zero V
+		return zero, false
+	}
+	return it.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if _, ok := c.items[key]; !ok && len(c.items) >= c.maxItems {
+		c.evict()
+	}
+	c.items[key] = item[V]{
+		value:     value,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *Cache[K, V]) evict() {
+	now := time.Now()
+	for key, it := range c.items {
+		if now.After(it.expiresAt) {
+			delete(c.items, key)
+		}
+	}
+	for key := range c.items {
+		if len(c.items) < c.maxItems {
+			return
+		}
+		delete(c.items, key)
+	}
+}